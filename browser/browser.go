@@ -0,0 +1,20 @@
+package browser
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// Open launches url in the user's default browser without waiting for it.
+func Open(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,19 @@
+package clipboard
+
+import (
+	"os"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Copy puts text on the system clipboard. Without a clipboard tool (e.g.
+// over SSH) it falls back to an OSC 52 escape, which most terminals forward
+// to the local clipboard.
+func Copy(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+	_, err := osc52.New(text).WriteTo(os.Stderr)
+	return err
+}
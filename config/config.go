@@ -0,0 +1,456 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"termiflow/keyring"
+	"termiflow/quiet"
+	"termiflow/storage"
+)
+
+// file holds settings as {"NAME": "value"} using the same names as the
+// environment variables. Real environment variables win over the file.
+const file = "config.json"
+
+// Setting describes one configuration variable.
+type Setting struct {
+	Name    string
+	Secret  bool // masked when listed and never edited in-app
+	Restart bool // only read at startup, so edits apply next run
+	check   func(string) error
+}
+
+// Validation for setting values; "" always passes since it means unset.
+var (
+	anyValue = func(string) error { return nil }
+	flag     = oneOf("0", "1")
+)
+
+func oneOf(values ...string) func(string) error {
+	return func(v string) error {
+		if !slices.Contains(values, v) {
+			return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+func duration(v string) error {
+	if d, err := time.ParseDuration(v); err != nil || d < 0 {
+		return fmt.Errorf("must be a duration like 30s or 5m")
+	}
+	return nil
+}
+
+func clockRange(v string) error {
+	_, _, err := quiet.ParseRange(v)
+	return err
+}
+
+func positiveInt(v string) error {
+	if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+		return fmt.Errorf("must be a positive number")
+	}
+	return nil
+}
+
+func absoluteURL(v string) error {
+	if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("must be a URL like https://example.com")
+	}
+	return nil
+}
+
+func repoList(v string) error {
+	for _, r := range strings.Split(v, ",") {
+		owner, name, ok := strings.Cut(strings.TrimSpace(r), "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("%q is not owner/name", strings.TrimSpace(r))
+		}
+	}
+	return nil
+}
+
+func regexList(v string) error {
+	_, err := Patterns(v)
+	return err
+}
+
+// Patterns parses a list of regular expressions written as a JSON list,
+// e.g. ["kubectl delete", "curl .*\\| *sh"], since a pattern may contain any
+// separator. A value that isn't a list is one pattern. It returns the
+// patterns that compile and an error naming the first that doesn't.
+func Patterns(v string) ([]*regexp.Regexp, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	list := []string{v}
+	if strings.HasPrefix(v, "[") {
+		if err := json.Unmarshal([]byte(v), &list); err != nil {
+			return nil, fmt.Errorf(`must be a JSON list like ["kubectl delete", "terraform destroy"]`)
+		}
+	}
+	var res []*regexp.Regexp
+	var err error
+	for _, p := range list {
+		re, cerr := regexp.Compile(p)
+		if cerr != nil {
+			err = cmp.Or(err, fmt.Errorf("%q is not a regular expression", p))
+			continue
+		}
+		res = append(res, re)
+	}
+	return res, err
+}
+
+// Settings lists every variable the app reads, in README order.
+var Settings = []Setting{
+	{Name: "GITHUB_TOKEN", Secret: true},
+	{Name: "GITHUB_REPO", Restart: true, check: repoList},
+	{Name: "GITHUB_REPOS", Restart: true, check: repoList},
+	{Name: "GITHUB_SORT", Restart: true, check: oneOf("created", "updated", "comments")},
+	{Name: "GITHUB_DIRECTION", Restart: true, check: oneOf("desc", "asc")},
+	{Name: "GITHUB_PER_PAGE", check: positiveInt},
+	{Name: "JIRA_URL", check: absoluteURL},
+	{Name: "JIRA_EMAIL", check: anyValue},
+	{Name: "JIRA_TOKEN", Secret: true},
+	{Name: "JIRA_AUTH", check: oneOf("basic", "bearer")},
+	{Name: "JIRA_SEARCH_API", check: oneOf("jql", "legacy")},
+	{Name: "JIRA_EXTRA_FIELDS", check: anyValue},
+	{Name: "JIRA_ALL_JQL", check: anyValue},
+	{Name: "JIRA_MAX_RESULTS", check: positiveInt},
+	{Name: "JIRA_CA_FILE", check: existingFile},
+	{Name: "JIRA_INSECURE_SKIP_VERIFY", check: flag},
+	{Name: "TERMIFLOW_LIST_WRAP", Restart: true, check: flag},
+	{Name: "GEMINI_API_KEY", Secret: true},
+	{Name: "GEMINI_MODEL", check: anyValue},
+	{Name: "GEMINI_MODELS", check: anyValue},
+	{Name: "GEMINI_BACKEND", check: oneOf("apikey", "vertex")},
+	{Name: "GOOGLE_CLOUD_PROJECT", check: anyValue},
+	{Name: "GOOGLE_CLOUD_LOCATION", check: anyValue},
+	{Name: "GEMINI_BASE_URL", check: absoluteURL},
+	{Name: "GEMINI_TOOL_APPROVAL", Restart: true, check: flag},
+	{Name: "GEMINI_KEEP_TURNS", check: positiveInt},
+	{Name: "GEMINI_AUTO_RETRY", check: flag},
+	{Name: "TERMIFLOW_CHAT_CACHE", check: flag},
+	{Name: "TERMIFLOW_CHAT_MAX_CHARS", check: positiveInt},
+	{Name: "GLAMOUR_STYLE", check: anyValue},
+	{Name: "TERMIFLOW_INLINE_IMAGES", check: flag},
+	{Name: "TERMIFLOW_SHELL_TIMESTAMPS", Restart: true, check: flag},
+	{Name: "TERMIFLOW_SHELL_WRAP", Restart: true, check: flag},
+	{Name: "TERMIFLOW_SHELL_MOTD", Restart: true, check: anyValue},
+	{Name: "TERMIFLOW_SHELL_INIT", Restart: true, check: anyValue},
+	{Name: "TERMIFLOW_SHELL_BINARY", check: oneOf("raw")},
+	{Name: "TERMIFLOW_SHELL_SCROLLBACK", Restart: true, check: positiveInt},
+	{Name: "TERMIFLOW_SHELL_LOG", Restart: true, check: flag},
+	{Name: "TERMIFLOW_SHELL_CONFIRM", check: flag},
+	{Name: "TERMIFLOW_SHELL_DANGEROUS", check: regexList},
+	{Name: "TERMIFLOW_TREE_DEPTH", check: positiveInt},
+	{Name: "TERMIFLOW_INTERACTIVE", check: anyValue},
+	{Name: "TERMIFLOW_TREE_IGNORE", check: anyValue},
+	{Name: "TERMIFLOW_MAX_CONCURRENT", Restart: true, check: positiveInt},
+	{Name: "TERMIFLOW_PROXY", check: absoluteURL},
+	{Name: "TERMIFLOW_REFRESH_INTERVAL", Restart: true, check: duration},
+	{Name: "TERMIFLOW_QUIET_HOURS", check: clockRange},
+	{Name: "TERMIFLOW_NOTIFY", check: flag},
+	{Name: "TERMIFLOW_NOTIFY_AFTER", check: duration},
+	{Name: "TERMIFLOW_DEFAULT_TAB", Restart: true, check: anyValue},
+	{Name: "TERMIFLOW_STDIN", Restart: true, check: oneOf("chat", "shell")},
+	{Name: "TERMIFLOW_KEYRING", Restart: true, check: flag},
+}
+
+func existingFile(v string) error {
+	if info, err := os.Stat(v); err != nil || info.IsDir() {
+		return fmt.Errorf("no such file")
+	}
+	return nil
+}
+
+// Lookup finds a setting by name, ignoring case.
+func Lookup(name string) (Setting, bool) {
+	for _, s := range Settings {
+		if strings.EqualFold(s.Name, name) {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// fromEnv records the variables set in the real environment at startup, so
+// the file doesn't pretend to control them.
+var fromEnv = map[string]bool{}
+
+// fromFlag records the variables overridden by command-line flags.
+var fromFlag = map[string]bool{}
+
+// fromKeyring records the secrets read from the OS keyring.
+var fromKeyring = map[string]bool{}
+
+// looked records the secrets already looked up in the OS keyring. Lookups
+// wait for the first Secret call, so starting up never waits on a locked
+// or missing keyring; keyringMu guards both maps, since fetches read
+// secrets off the UI goroutine.
+var (
+	keyringMu sync.Mutex
+	looked    = map[string]bool{}
+)
+
+// Load applies the config file, then the project's .termiflowrc over it,
+// to the environment for every setting not already set there. Secrets in
+// the OS keyring, which win over the file, are filled in by Secret when
+// first needed. Call it before anything reads the environment.
+func Load() error {
+	for _, s := range Settings {
+		if _, ok := os.LookupEnv(s.Name); ok {
+			fromEnv[s.Name] = true
+		}
+	}
+	values, err := read()
+	project, projectErr := readProject()
+	for name, v := range values {
+		if _, known := Lookup(name); known && !fromEnv[name] {
+			os.Setenv(name, v)
+		}
+	}
+	for name, v := range project {
+		if !fromEnv[name] {
+			os.Setenv(name, v)
+			fromProject[name] = true
+		}
+	}
+	return errors.Join(err, projectErr)
+}
+
+// Secret returns the value of a secret setting, looking it up in the OS
+// keyring on first use unless the environment or a flag set it. Read
+// secrets through Secret rather than os.Getenv so keyring values are seen.
+func Secret(name string) string {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	if !looked[name] {
+		looked[name] = true
+		lookupKeyring(name)
+	}
+	return os.Getenv(name)
+}
+
+// lookupKeyring fills in a secret from the OS keyring. The caller holds
+// keyringMu.
+func lookupKeyring(name string) {
+	if fromEnv[name] || fromFlag[name] || !KeyringEnabled() {
+		return
+	}
+	if v, err := keyring.Get(name); err == nil {
+		os.Setenv(name, v)
+		fromKeyring[name] = true
+	}
+}
+
+// Changes names the settings a Reload changed.
+type Changes map[string]bool
+
+// Any reports whether a changed setting starts with one of prefixes.
+func (c Changes) Any(prefixes ...string) bool {
+	for name := range c {
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Reload re-reads the config file, the .termiflowrc and the keyring after
+// they were edited outside the app. Settings from the real environment or a
+// flag keep their value; others removed from the files are unset, except
+// secrets, which may have been entered in-app (/setup). On error nothing
+// changes.
+func Reload() (Changes, error) {
+	before := map[string]string{}
+	for _, s := range Settings {
+		before[s.Name] = os.Getenv(s.Name)
+	}
+	values, err := read()
+	if err != nil {
+		return nil, err
+	}
+	project, err := readProject()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range Settings {
+		if fromEnv[s.Name] || fromFlag[s.Name] {
+			continue
+		}
+		fromProject[s.Name] = false
+		if v, ok := project[s.Name]; ok {
+			os.Setenv(s.Name, v)
+			fromProject[s.Name] = true
+		} else if v, ok := values[s.Name]; ok {
+			os.Setenv(s.Name, v)
+		} else if !s.Secret {
+			os.Unsetenv(s.Name)
+		}
+	}
+	// Secrets already in use are looked up again; the rest stay lazy
+	keyringMu.Lock()
+	for name := range looked {
+		lookupKeyring(name)
+	}
+	keyringMu.Unlock()
+	changed := Changes{}
+	for _, s := range Settings {
+		if os.Getenv(s.Name) != before[s.Name] {
+			changed[s.Name] = true
+		}
+	}
+	return changed, nil
+}
+
+// KeyringEnabled reports whether secrets are looked up in the OS keyring:
+// unless TERMIFLOW_KEYRING=0, whenever one is available. The first call
+// probes the keyring, which takes up to a second when there is none.
+func KeyringEnabled() bool {
+	return os.Getenv("TERMIFLOW_KEYRING") != "0" && keyring.Available()
+}
+
+// SaveSecret stores a secret setting in the OS keyring and applies it to
+// the running process, so it is used now and on every later run.
+func SaveSecret(name, value string) error {
+	s, ok := Lookup(name)
+	if !ok || !s.Secret {
+		return fmt.Errorf("%s is not a secret setting", name)
+	}
+	if err := keyring.Set(s.Name, value); err != nil {
+		return err
+	}
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	looked[s.Name] = true
+	os.Setenv(s.Name, value)
+	fromEnv[s.Name] = false
+	fromKeyring[s.Name] = true
+	return nil
+}
+
+func read() (map[string]string, error) {
+	values := map[string]string{}
+	err := storage.LoadJSON(file, &values)
+	if values == nil {
+		values = map[string]string{}
+	}
+	return values, err
+}
+
+// Entry is a setting's current value for display.
+type Entry struct {
+	Name   string
+	Value  string // masked for secrets
+	Source string // "flag", "env", "keyring", "project", "file" or "" when unset
+}
+
+// List returns every setting with its current value.
+func List() []Entry {
+	var entries []Entry
+	for _, s := range Settings {
+		e := Entry{Name: s.Name, Value: os.Getenv(s.Name)}
+		if s.Secret {
+			e.Value = Secret(s.Name)
+		}
+		switch {
+		case e.Value == "":
+		case fromFlag[s.Name]:
+			e.Source = "flag"
+		case fromKeyring[s.Name]:
+			e.Source = "keyring"
+		case fromEnv[s.Name]:
+			e.Source = "env"
+		case fromProject[s.Name]:
+			e.Source = "project"
+		default:
+			e.Source = "file"
+		}
+		if s.Secret && e.Value != "" {
+			e.Value = mask(e.Value)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// mask keeps only the last four characters of a secret.
+func mask(v string) string {
+	if len(v) <= 4 {
+		return "****"
+	}
+	return "****" + v[len(v)-4:]
+}
+
+// Set validates value, saves it to the config file and applies it to the
+// running process. An empty value removes the setting.
+func Set(name, value string) (Setting, error) {
+	s, ok := Lookup(name)
+	if !ok {
+		return s, fmt.Errorf("unknown setting %s", name)
+	}
+	if s.Secret {
+		return s, fmt.Errorf("%s is a secret; set it in your environment instead", s.Name)
+	}
+	if value != "" {
+		if err := s.check(value); err != nil {
+			return s, fmt.Errorf("%s %v", s.Name, err)
+		}
+	}
+	values, err := read()
+	if err != nil {
+		return s, err
+	}
+	if value == "" {
+		delete(values, s.Name)
+	} else {
+		values[s.Name] = value
+	}
+	if err := storage.SaveJSON(file, values); err != nil {
+		return s, err
+	}
+	if value == "" {
+		os.Unsetenv(s.Name)
+	} else {
+		os.Setenv(s.Name, value)
+	}
+	fromEnv[s.Name] = false // the in-app edit now overrides the environment
+	fromFlag[s.Name] = false
+	fromProject[s.Name] = false
+	return s, nil
+}
+
+// Override validates value and applies it to the running process only, for
+// command-line flags. Call it after Load so it wins over the file.
+func Override(name, value string) error {
+	s, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown setting %s", name)
+	}
+	if s.Secret {
+		return fmt.Errorf("%s is a secret; set it in your environment instead", s.Name)
+	}
+	if err := s.check(value); err != nil {
+		return fmt.Errorf("%s %v", s.Name, err)
+	}
+	os.Setenv(s.Name, value)
+	fromFlag[s.Name] = true
+	return nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectFile is the per-project config: a YAML mapping of setting names
+// to values, found in the working directory or one of its parents. It
+// overrides the global config file; the real environment overrides both.
+const projectFile = ".termiflowrc"
+
+// projectPath is the .termiflowrc in use, "" when there is none.
+var projectPath string
+
+// fromProject records the variables set by the .termiflowrc.
+var fromProject = map[string]bool{}
+
+// ProjectFile returns the path of the .termiflowrc in use, if any.
+func ProjectFile() string { return projectPath }
+
+// findProject returns the nearest .termiflowrc at or above the working
+// directory.
+func findProject() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		p := filepath.Join(dir, projectFile)
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readProject reads the nearest .termiflowrc. Names match settings in any
+// case; unknown names, invalid values and secrets, which don't belong in a
+// file that's likely checked in, are errors.
+func readProject() (map[string]string, error) {
+	projectPath = findProject()
+	if projectPath == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", projectPath, err)
+	}
+	values := map[string]string{}
+	for name, v := range raw {
+		s, ok := Lookup(name)
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%s: unknown setting %s", projectPath, name)
+		case s.Secret:
+			return nil, fmt.Errorf("%s: %s is a secret; set it in your environment or keyring instead", projectPath, s.Name)
+		case v != "":
+			if err := s.check(v); err != nil {
+				return nil, fmt.Errorf("%s: %s %v", projectPath, s.Name, err)
+			}
+		}
+		values[s.Name] = v
+	}
+	return values, nil
+}
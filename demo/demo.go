@@ -0,0 +1,25 @@
+package demo
+
+import (
+	"embed"
+	"os"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Enabled reports whether TERMIFLOW_DEMO=1, in which case integrations serve
+// canned fixture data instead of calling real APIs.
+func Enabled() bool {
+	return os.Getenv("TERMIFLOW_DEMO") == "1"
+}
+
+// Fixture returns the embedded fixture fixtures/<name>.json.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		// Fixtures are compiled in, so this is a programming error
+		panic(err)
+	}
+	return data
+}
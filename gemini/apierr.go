@@ -0,0 +1,122 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// -- API Errors --
+
+var (
+	errInvalidKey = errors.New("Your GEMINI_API_KEY appears invalid — check it at https://aistudio.google.com/apikey")
+	errVertexAuth = errors.New("Vertex AI rejected your credentials — run `gcloud auth application-default login` and check GOOGLE_CLOUD_PROJECT")
+	errQuota      = errors.New("Gemini quota exceeded — wait a minute and try again, or upgrade your plan at https://aistudio.google.com")
+)
+
+// FriendlyError replaces authentication, quota and safety failures from
+// the Gemini API with actionable messages. Other errors are returned
+// unchanged.
+func FriendlyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var blocked *genai.BlockedError
+	if errors.As(err, &blocked) {
+		return &blockedError{blocked}
+	}
+	httpCode, code, reason := apiErrorInfo(err)
+	switch {
+	case reason == "API_KEY_INVALID", httpCode == 401, httpCode == 403,
+		code == codes.Unauthenticated, code == codes.PermissionDenied,
+		strings.Contains(err.Error(), "API key not valid"):
+		if Vertex() {
+			return errVertexAuth
+		}
+		return errInvalidKey
+	case httpCode == 429, code == codes.ResourceExhausted:
+		return &RateLimitError{Delay: retryDelay(err)}
+	}
+	return err
+}
+
+// RateLimitError is a 429 / RESOURCE_EXHAUSTED answer: the free tier's
+// requests-per-minute or per-day quota ran out. Delay is the wait the API
+// suggested, 0 when it gave none.
+type RateLimitError struct {
+	Delay time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Delay <= 0 {
+		return errQuota.Error()
+	}
+	return fmt.Sprintf("Rate limited — retry in %ds", int((e.Delay+time.Second-1)/time.Second))
+}
+
+// blockedError explains a prompt or answer Gemini refused, on safety or
+// recitation grounds. Sending it again gets the same refusal, so it is
+// never retried. It unwraps to the *genai.BlockedError.
+type blockedError struct {
+	err *genai.BlockedError
+}
+
+func (e *blockedError) Error() string {
+	if f := e.err.PromptFeedback; f != nil {
+		return fmt.Sprintf("Gemini blocked the question (%s); rephrase it and try again", strings.TrimPrefix(f.BlockReason.String(), "BlockReason"))
+	}
+	reason := "blocked"
+	if c := e.err.Candidate; c != nil {
+		reason = strings.TrimPrefix(c.FinishReason.String(), "FinishReason")
+	}
+	return fmt.Sprintf("Gemini stopped answering (%s); try rephrasing the question", reason)
+}
+
+func (e *blockedError) Unwrap() error { return e.err }
+
+// retryInMessage matches the hint in quota errors, e.g. "Please retry in
+// 22.49s", for transports that drop the structured RetryInfo.
+var retryInMessage = regexp.MustCompile(`retry in (\d+(?:\.\d+)?)s`)
+
+// retryDelay returns the RetryInfo delay attached to err, or 0.
+func retryDelay(err error) time.Duration {
+	var ae *apierror.APIError
+	if errors.As(err, &ae) {
+		if ri := ae.Details().RetryInfo; ri != nil {
+			return ri.GetRetryDelay().AsDuration()
+		}
+	}
+	if m := retryInMessage.FindStringSubmatch(err.Error()); m != nil {
+		if secs, perr := strconv.ParseFloat(m[1], 64); perr == nil {
+			return time.Duration(secs * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// apiErrorInfo extracts the HTTP status, gRPC code and error reason from an
+// error returned by the genai client, whichever transport produced it.
+func apiErrorInfo(err error) (int, codes.Code, string) {
+	var ae *apierror.APIError
+	if errors.As(err, &ae) {
+		code := codes.Unknown
+		if s := ae.GRPCStatus(); s != nil {
+			code = s.Code()
+		}
+		return ae.HTTPCode(), code, ae.Reason()
+	}
+	var ge *googleapi.Error
+	if errors.As(err, &ge) {
+		return ge.Code, codes.Unknown, ""
+	}
+	return 0, status.Code(err), ""
+}
@@ -0,0 +1,58 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestFriendlyErrorBlocked(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"prompt", &genai.BlockedError{PromptFeedback: &genai.PromptFeedback{BlockReason: genai.BlockReasonSafety}},
+			"Gemini blocked the question (Safety); rephrase it and try again"},
+		{"answer", &genai.BlockedError{Candidate: &genai.Candidate{FinishReason: genai.FinishReasonRecitation}},
+			"Gemini stopped answering (Recitation); try rephrasing the question"},
+		{"wrapped", fmt.Errorf("send: %w", &genai.BlockedError{Candidate: &genai.Candidate{FinishReason: genai.FinishReasonSafety}}),
+			"Gemini stopped answering (Safety); try rephrasing the question"},
+		{"other errors unchanged", errors.New("boom"), "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FriendlyError(tt.err)
+			if err.Error() != tt.want {
+				t.Errorf("got %q, want %q", err, tt.want)
+			}
+			var blocked *genai.BlockedError
+			if errors.As(tt.err, &blocked) != errors.As(err, &blocked) {
+				t.Errorf("%v no longer unwraps to the *genai.BlockedError", err)
+			}
+		})
+	}
+}
+
+func TestEmptyResponseError(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *genai.GenerateContentResponse
+		want string
+	}{
+		{"no response", nil, "Gemini sent an empty response twice in a row; try again or rephrase the question"},
+		{"no reason", &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{}}},
+			"Gemini sent an empty response twice in a row; try again or rephrase the question"},
+		{"finish reason", &genai.GenerateContentResponse{Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonMaxTokens}}},
+			"Gemini sent an empty response twice in a row (finish reason: MaxTokens); try rephrasing the question"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EmptyResponseError(tt.resp).Error(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package gemini connects to Gemini through the configured backend. The
+// chat tab and one-off assists in other tabs share it.
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"termiflow/config"
+	"termiflow/httpclient"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// -- Backends --
+
+// GEMINI_BACKEND selects how Gemini is reached: the public Generative
+// Language API with GEMINI_API_KEY (default), or Vertex AI with Application
+// Default Credentials.
+const (
+	backendAPIKey = "apikey"
+	backendVertex = "vertex"
+)
+
+func backend() string {
+	if strings.EqualFold(os.Getenv("GEMINI_BACKEND"), backendVertex) {
+		return backendVertex
+	}
+	return backendAPIKey
+}
+
+// Vertex reports whether Gemini is reached through Vertex AI.
+func Vertex() bool { return backend() == backendVertex }
+
+// Configured reports whether credentials are set up: an API key, or Vertex
+// AI, which relies on Application Default Credentials that are only checked
+// on connect.
+func Configured() bool {
+	return Vertex() || config.Secret("GEMINI_API_KEY") != ""
+}
+
+// ModelName returns GEMINI_MODEL, without the Vertex prefix.
+func ModelName() string {
+	if name := os.Getenv("GEMINI_MODEL"); name != "" {
+		return name
+	}
+	return "gemini-1.5-flash-002" // Latest stable flash
+}
+
+// NewClient connects with the current settings.
+func NewClient(ctx context.Context) (*genai.Client, error) {
+	opts, err := clientOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return genai.NewClient(ctx, opts...)
+}
+
+// clientOptions builds the genai client options for the configured backend.
+// GEMINI_BASE_URL overrides the endpoint in either mode, e.g. for a proxy.
+func clientOptions(ctx context.Context) ([]option.ClientOption, error) {
+	var opts []option.ClientOption
+	switch backend() {
+	case backendVertex:
+		project, location := vertexProject()
+		if project == "" {
+			return nil, fmt.Errorf("GOOGLE_CLOUD_PROJECT must be set for GEMINI_BACKEND=vertex")
+		}
+		// ADC with the cloud-platform scope, layered on our proxy-aware transport
+		t, err := htransport.NewTransport(ctx, httpclient.Transport(),
+			option.WithScopes("https://www.googleapis.com/auth/cloud-platform"))
+		if err != nil {
+			return nil, fmt.Errorf("Vertex AI credentials: %w", err)
+		}
+		opts = append(opts,
+			option.WithHTTPClient(&http.Client{Transport: &vertexTransport{base: t}}),
+			option.WithEndpoint(fmt.Sprintf("https://%s-aiplatform.googleapis.com", location)))
+	default:
+		apiKey := config.Secret("GEMINI_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("GEMINI_API_KEY environment variable not set")
+		}
+		// Route Gemini traffic through our proxy-aware transport. The REST
+		// clients ignore WithAPIKey when given an HTTP client, so the key is
+		// sent as a header; the gRPC cache client still uses WithAPIKey.
+		hc := &http.Client{Transport: &apiKeyTransport{key: apiKey, base: httpclient.Transport()}}
+		opts = append(opts, option.WithHTTPClient(hc), option.WithAPIKey(apiKey))
+	}
+	if u := os.Getenv("GEMINI_BASE_URL"); u != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimSuffix(u, "/")))
+	}
+	return opts, nil
+}
+
+// vertexProject returns GOOGLE_CLOUD_PROJECT and GOOGLE_CLOUD_LOCATION,
+// defaulting the location to us-central1.
+func vertexProject() (string, string) {
+	location := os.Getenv("GOOGLE_CLOUD_LOCATION")
+	if location == "" {
+		location = "us-central1"
+	}
+	return os.Getenv("GOOGLE_CLOUD_PROJECT"), location
+}
+
+// FullModelName qualifies name with the Vertex publisher path when needed.
+// The Generative Language API takes the bare name.
+func FullModelName(name string) string {
+	if backend() != backendVertex || strings.Contains(name, "/") {
+		return name
+	}
+	project, location := vertexProject()
+	return fmt.Sprintf("projects/%s/locations/%s/publishers/google/models/%s", project, location, name)
+}
+
+type apiKeyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("x-goog-api-key", t.key)
+	return t.base.RoundTrip(req)
+}
+
+// vertexTransport maps the Generative Language API's /v1beta paths onto
+// Vertex AI's /v1 ones; request and response bodies are compatible.
+type vertexTransport struct {
+	base http.RoundTripper
+}
+
+func (t *vertexTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rest, ok := strings.CutPrefix(req.URL.Path, "/v1beta/"); ok {
+		req = req.Clone(req.Context())
+		req.URL.Path = "/v1/" + rest
+		req.URL.RawPath = ""
+	}
+	return t.base.RoundTrip(req)
+}
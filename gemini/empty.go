@@ -0,0 +1,36 @@
+package gemini
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Empty Responses --
+
+// EmptyRetryDelay is how long to wait before asking again after an empty
+// response. They are usually transient, so one retry is worth it; a second
+// empty response is reported instead of looping on a prompt Gemini won't
+// answer.
+const EmptyRetryDelay = 2 * time.Second
+
+// Empty reports whether resp has nothing to show: no candidate, or one
+// without content.
+func Empty(resp *genai.GenerateContentResponse) bool {
+	return resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil ||
+		len(resp.Candidates[0].Content.Parts) == 0
+}
+
+// EmptyResponseError explains an empty response with the candidate's
+// finish reason, when Gemini gave one. Blocked prompts don't get here: the
+// client returns them as a *genai.BlockedError, see FriendlyError.
+func EmptyResponseError(resp *genai.GenerateContentResponse) error {
+	if resp != nil && len(resp.Candidates) > 0 {
+		if fr := resp.Candidates[0].FinishReason; fr != genai.FinishReasonUnspecified {
+			return fmt.Errorf("Gemini sent an empty response twice in a row (finish reason: %s); try rephrasing the question", strings.TrimPrefix(fr.String(), "FinishReason"))
+		}
+	}
+	return fmt.Errorf("Gemini sent an empty response twice in a row; try again or rephrase the question")
+}
@@ -0,0 +1,43 @@
+package gemini
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// Generate answers a single prompt without a conversation, for quick
+// assists outside the chat. Each call opens and closes its own client.
+func Generate(ctx context.Context, prompt string) (string, error) {
+	c, err := NewClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer c.Close()
+
+	model := c.GenerativeModel(FullModelName(ModelName()))
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err == nil && Empty(resp) {
+		select {
+		case <-time.After(EmptyRetryDelay):
+			resp, err = model.GenerateContent(ctx, genai.Text(prompt))
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if err != nil {
+		return "", FriendlyError(err)
+	}
+	if Empty(resp) {
+		return "", EmptyResponseError(resp)
+	}
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			sb.WriteString(string(t))
+		}
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
@@ -0,0 +1,120 @@
+package gitrepo
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Fallback is shown when no repository is detected or configured.
+const Fallback = "charmbracelet/bubbletea"
+
+// Default returns the owner/name the GitHub tab and tool should use: the
+// GitHub origin of the current directory's git repo, else GITHUB_REPO, else
+// Fallback.
+func Default() string {
+	if repo, ok := Detect(); ok {
+		return repo
+	}
+	if repo := os.Getenv("GITHUB_REPO"); repo != "" {
+		return repo
+	}
+	return Fallback
+}
+
+// Detect returns owner/name for the origin remote of the git repo containing
+// the working directory, if that remote is on GitHub.
+func Detect() (string, bool) {
+	remote := originURL()
+	if remote == "" {
+		return "", false
+	}
+	return ParseRemote(remote)
+}
+
+// originURL asks git for the origin URL, falling back to reading
+// .git/config directly when git isn't installed.
+func originURL() string {
+	if out, err := exec.Command("git", "remote", "get-url", "origin").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		if url := configOrigin(filepath.Join(dir, ".git", "config")); url != "" {
+			return url
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// configOrigin reads the url of [remote "origin"] from a git config file.
+func configOrigin(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// ParseRemote extracts owner/name from a GitHub remote URL in any of the
+// forms git accepts: git@github.com:o/r.git, https://github.com/o/r,
+// ssh://git@github.com/o/r.git.
+func ParseRemote(remote string) (string, bool) {
+	var path string
+	switch {
+	case strings.HasPrefix(remote, "git@github.com:"):
+		path = strings.TrimPrefix(remote, "git@github.com:")
+	default:
+		_, rest, ok := strings.Cut(remote, "://")
+		if !ok {
+			return "", false
+		}
+		host, p, ok := strings.Cut(rest, "/")
+		if !ok {
+			return "", false
+		}
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+		if h, _, found := strings.Cut(host, ":"); found {
+			host = h // drop port
+		}
+		if host != "github.com" && host != "www.github.com" {
+			return "", false
+		}
+		path = p
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return owner + "/" + name, true
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"termiflow/config"
+	"termiflow/keyring"
+	"termiflow/ui/github"
+	"termiflow/ui/jira"
+
+	"golang.org/x/term"
+)
+
+// runHeadless runs a subcommand that prints issues to stdout instead of
+// starting the TUI, reusing the tabs' fetch logic. It returns the exit code.
+func runHeadless(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "jira":
+		fs := flag.NewFlagSet("jira", flag.ExitOnError)
+		jql := fs.String("jql", "", "Jira search (default: issues assigned to you)")
+		fs.Parse(args[1:])
+		return printJira(ctx, os.Stdout, *jql)
+	case "github":
+		fs := flag.NewFlagSet("github", flag.ExitOnError)
+		repo := fs.String("repo", "", "repos as owner/name[,owner/name...] (default: GITHUB_REPOS or the current repo)")
+		fs.Parse(args[1:])
+		if *repo != "" {
+			if err := config.Override("GITHUB_REPOS", *repo); err != nil {
+				fmt.Fprintf(os.Stderr, "termiflow: %v\n", err)
+				return 2
+			}
+		}
+		return printGitHub(ctx, os.Stdout)
+	case "keyring":
+		return keyringCommand(args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "termiflow: unknown command %q\n", args[0])
+	flag.Usage()
+	return 2
+}
+
+// printJira prints one issue per line as key, status and summary.
+func printJira(ctx context.Context, w io.Writer, jql string) int {
+	if jql == "" {
+		jql = jira.MyJQL
+	}
+	result, err := jira.Search(ctx, jql)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "termiflow: %v\n", err)
+		return 1
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, issue := range result.Issues {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", issue.Key, issue.Fields.Status.Name, issue.Fields.Summary)
+	}
+	tw.Flush()
+	return 0
+}
+
+// printGitHub prints one issue per line as repo#number, labels and title.
+// Repos that failed are reported on stderr after the ones that didn't.
+func printGitHub(ctx context.Context, w io.Writer) int {
+	issues, err := github.Fetch(ctx, github.ConfiguredRepos())
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, issue := range issues {
+		var labels []string
+		for _, l := range issue.Labels {
+			labels = append(labels, l.Name)
+		}
+		fmt.Fprintf(tw, "%s#%d\t%s\t%s\n", issue.Repo, issue.Number, strings.Join(labels, ","), issue.Title)
+	}
+	tw.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "termiflow: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// keyringCommand stores or removes a secret setting in the OS keyring:
+// `termiflow keyring set GITHUB_TOKEN` prompts for the value (or reads it
+// from a pipe), `termiflow keyring delete GITHUB_TOKEN` removes it.
+func keyringCommand(args []string) int {
+	if len(args) != 2 || (args[0] != "set" && args[0] != "delete") {
+		fmt.Fprintln(os.Stderr, "Usage: termiflow keyring set|delete NAME")
+		return 2
+	}
+	s, ok := config.Lookup(args[1])
+	if !ok || !s.Secret {
+		fmt.Fprintf(os.Stderr, "termiflow: %s is not a secret setting\n", args[1])
+		return 2
+	}
+	if !keyring.Available() {
+		fmt.Fprintf(os.Stderr, "termiflow: %v\n", keyring.ErrUnavailable)
+		return 1
+	}
+	if args[0] == "delete" {
+		if err := keyring.Delete(s.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "termiflow: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	value, err := readSecret(s.Name)
+	if err == nil && value == "" {
+		err = fmt.Errorf("no value given")
+	}
+	if err == nil {
+		err = config.SaveSecret(s.Name, value)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "termiflow: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Saved %s to the OS keyring.\n", s.Name)
+	return 0
+}
+
+// readSecret prompts for a value without echoing it, or reads the first
+// line of piped input.
+func readSecret(name string) (string, error) {
+	if stdinPiped() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", name)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return strings.TrimSpace(string(b)), err
+}
@@ -0,0 +1,68 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"termiflow/storage"
+)
+
+// Events scripts can subscribe to.
+const (
+	CommandDone    = "on_command_done"     // a shell command exited
+	NewGitHubIssue = "on_new_github_issue" // an unseen issue appeared in the GitHub tab
+	ChatResponse   = "on_chat_response"    // Gemini answered in the chat
+)
+
+// hooksFile maps event names to commands, e.g.
+//
+//	{"on_command_done": "~/bin/notify-done", "on_new_github_issue": "slack-post #triage"}
+//
+// Each command gets {"event": ..., "time": ..., "data": {...}} on stdin.
+const hooksFile = "hooks.json"
+
+// timeout kills hooks that run too long so they don't pile up.
+const timeout = 10 * time.Second
+
+var (
+	loadOnce sync.Once
+	commands map[string]string
+)
+
+// Fire runs the hook for event, if one is configured, in the background
+// with data as JSON on stdin. It never blocks; failures are ignored since
+// there's nowhere to report them without disturbing the UI.
+func Fire(event string, data any) {
+	loadOnce.Do(func() {
+		_ = storage.LoadJSON(hooksFile, &commands) // missing or invalid: no hooks
+	})
+	args := strings.Fields(commands[event])
+	if len(args) == 0 {
+		return
+	}
+	if rest, ok := strings.CutPrefix(args[0], "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			args[0] = filepath.Join(home, rest)
+		}
+	}
+	payload, err := json.Marshal(map[string]any{"event": event, "time": time.Now().Format(time.RFC3339), "data": data})
+	if err != nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.WaitDelay = time.Second
+		_ = cmd.Run()
+	}()
+}
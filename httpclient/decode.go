@@ -0,0 +1,84 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// snippetRadius is how many bytes of body are quoted on each side of a
+// decode error.
+const snippetRadius = 40
+
+// displayNames spell the integrations as errors show them.
+var displayNames = map[string]string{GitHub: "GitHub", Jira: "Jira"}
+
+// DisplayName returns how messages spell integration, e.g. "GitHub".
+func DisplayName(integration string) string {
+	if name, ok := displayNames[integration]; ok {
+		return name
+	}
+	return integration
+}
+
+// DecodeJSON decodes resp's body into v. Errors name the integration (GitHub
+// or Jira) and HTTP status and, when the body is malformed or has an
+// unexpected shape, quote it around the offending offset so API changes are
+// easy to spot.
+func DecodeJSON(resp *http.Response, integration string, v any) error {
+	integration = DisplayName(integration)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: reading %s response: %w", integration, resp.Status, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return decodeError(integration, resp.Status, data, err)
+	}
+	return nil
+}
+
+func decodeError(integration, status string, data []byte, err error) error {
+	var offset int64 = -1
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		// Offset counts the offending byte; point at it, not past it
+		offset = max(syntax.Offset-1, 0)
+	case errors.As(err, &typ):
+		offset = typ.Offset
+	}
+	if offset < 0 {
+		return fmt.Errorf("%s: decoding %s response: %w", integration, status, err)
+	}
+	line, col := position(data, offset)
+	return fmt.Errorf("%s: decoding %s response at line %d, column %d: %w (near %q)",
+		integration, status, line, col, err, snippet(data, offset))
+}
+
+// position converts a byte offset into a 1-based line and column.
+func position(data []byte, offset int64) (line, col int) {
+	before := data[:min(int(offset), len(data))]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// snippet returns the body around offset on one line.
+func snippet(data []byte, offset int64) string {
+	at := min(int(offset), len(data))
+	start, end := max(at-snippetRadius, 0), min(at+snippetRadius, len(data))
+	s := strings.ToValidUTF8(string(data[start:end]), "")
+	s = strings.Join(strings.Fields(s), " ")
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(data) {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type issue struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	tests := []struct {
+		name        string
+		integration string
+		body        string
+		want        []string // parts of the error, none for success
+	}{
+		{"ok", GitHub, `[{"number": 1, "title": "Bug"}]`, nil},
+		{"truncated", GitHub, `[{"number": 1, "title": "Bu`,
+			[]string{"GitHub: decoding 200 OK response", "unexpected end of JSON input"}},
+		{"malformed", Jira, "[\n  {\"number\": 1,,}\n]",
+			[]string{"Jira: decoding 200 OK response at line 2, column 16", "invalid character ','", `(near "[ {\"number\": 1,,} ]")`}},
+		{"wrong shape", GitHub, `[{"number": "one"}]`,
+			[]string{"GitHub: decoding 200 OK response at line 1, column 18", "cannot unmarshal string", `near "[{\"number\": \"one\"}]"`}},
+		{"html error page", Jira, `<html>Gateway Timeout</html>`,
+			[]string{"Jira: decoding 200 OK response at line 1, column 1", `near "<html>Gateway Timeout</html>"`}},
+		{"unknown integration keeps its name", "ci", `{`,
+			[]string{"ci: decoding 200 OK response"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Status: "200 OK", StatusCode: 200, Body: io.NopCloser(strings.NewReader(tt.body))}
+			var issues []issue
+			err := DecodeJSON(resp, tt.integration, &issues)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(issues) != 1 || issues[0].Title != "Bug" {
+					t.Errorf("decoded %+v", issues)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("error %q lacks %q", err, w)
+				}
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", 100) + "!" + strings.Repeat("b", 100)
+	tests := []struct {
+		name   string
+		data   string
+		offset int64
+		want   string
+	}{
+		{"short body whole", `{"a": x}`, 6, `{"a": x}`},
+		{"long body cut both ways", long, 100, "…" + strings.Repeat("a", 40) + strings.Repeat("!", 1) + strings.Repeat("b", 39) + "…"},
+		{"whitespace collapsed", "{\n\t\"a\":\n\n x}", 9, `{ "a": x}`},
+		{"offset past the end", "abc", 10, "abc"},
+		{"bad UTF-8 dropped", "ab\xffcd", 2, "abcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet([]byte(tt.data), tt.offset); got != tt.want {
+				t.Errorf("snippet = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package httpclient
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"termiflow/storage"
+	"termiflow/version"
+)
+
+// Integrations whose requests take extra headers from headersFile.
+const (
+	GitHub = "github"
+	Jira   = "jira"
+)
+
+// headersFile maps an integration to the headers added to each of its
+// requests, e.g. {"jira": {"X-Corp-Gateway": "${GATEWAY_TOKEN}"}}. Values
+// expand environment variables so secrets can stay out of the file.
+const headersFile = "headers.json"
+
+// UserAgent identifies the app and its version to the APIs it calls.
+func UserAgent() string {
+	return "TermiFlow/" + version.Version
+}
+
+// SetHeaders sets the User-Agent and the configured headers for integration
+// on req. Call it after the request's own headers: configured ones win, so
+// a proxy can be given whatever it insists on.
+func SetHeaders(req *http.Request, integration string) {
+	req.Header.Set("User-Agent", UserAgent())
+	extra, _ := Headers(integration) // unreadable file: no extra headers
+	for k, v := range extra {
+		req.Header.Set(k, v)
+	}
+}
+
+// Headers returns the configured headers for integration with environment
+// variables expanded.
+func Headers(integration string) (map[string]string, error) {
+	var all map[string]map[string]string
+	if err := storage.LoadJSON(headersFile, &all); err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	for k, v := range all[integration] {
+		headers[k] = os.ExpandEnv(v)
+	}
+	return headers, nil
+}
+
+// Describe lists headers as "Name: value" sorted by name, masking the
+// values of sensitive ones so they can be shown or logged.
+func Describe(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, k := range names {
+		v := headers[k]
+		if Sensitive(k) {
+			v = "****"
+		}
+		parts[i] = k + ": " + v
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Sensitive reports whether a header carries credentials.
+func Sensitive(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "authorization", "proxy-authorization", "cookie", "set-cookie":
+		return true
+	}
+	for _, word := range []string{"token", "secret", "key", "password", "auth", "session"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
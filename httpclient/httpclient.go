@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// New returns an HTTP client with the given timeout that goes through the
+// configured proxy (see Transport).
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: Transport(),
+	}
+}
+
+// Transport returns the shared transport for outbound requests: it uses the
+// configured proxy and goes through the shared concurrency limiter.
+func Transport() http.RoundTripper {
+	return &limitedTransport{base: baseTransport(), limiter: shared()}
+}
+
+// baseTransport is a copy of the default transport whose proxy is
+// TERMIFLOW_PROXY when set, otherwise HTTP_PROXY/HTTPS_PROXY/NO_PROXY.
+func baseTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = proxyFunc()
+	return t
+}
+
+func proxyFunc() func(*http.Request) (*url.URL, error) {
+	raw := os.Getenv("TERMIFLOW_PROXY")
+	if raw == "" {
+		return http.ProxyFromEnvironment
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		// Fail requests loudly rather than silently bypassing the proxy
+		return func(*http.Request) (*url.URL, error) {
+			return nil, fmt.Errorf("invalid TERMIFLOW_PROXY %q", raw)
+		}
+	}
+	return http.ProxyURL(u)
+}
+
+// TLSOptions customizes certificate verification for self-hosted services.
+type TLSOptions struct {
+	CAFile   string // PEM bundle added to the system roots
+	Insecure bool   // skip verification entirely; never the default
+}
+
+// TLSFromEnv reads <PREFIX>_CA_FILE and <PREFIX>_INSECURE_SKIP_VERIFY.
+func TLSFromEnv(prefix string) TLSOptions {
+	return TLSOptions{
+		CAFile:   os.Getenv(prefix + "_CA_FILE"),
+		Insecure: os.Getenv(prefix+"_INSECURE_SKIP_VERIFY") == "1",
+	}
+}
+
+// NewWithTLS is like New but applies the given TLS options.
+func NewWithTLS(timeout time.Duration, opts TLSOptions) (*http.Client, error) {
+	t := baseTransport()
+	if opts.CAFile != "" || opts.Insecure {
+		cfg := &tls.Config{InsecureSkipVerify: opts.Insecure}
+		if opts.CAFile != "" {
+			pem, err := os.ReadFile(opts.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", opts.CAFile)
+			}
+			cfg.RootCAs = pool
+		}
+		t.TLSClientConfig = cfg
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &limitedTransport{base: t, limiter: shared()},
+	}, nil
+}
@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -- Concurrency & Backoff --
+
+const (
+	defaultMaxConcurrent = 2
+	defaultBackoff       = 10 * time.Second
+	// Longer backoffs fail fast instead of stalling a fetch command
+	maxBackoffWait = 5 * time.Second
+)
+
+// limiter bounds how many requests are in flight across every integration
+// and remembers hosts that asked us to back off.
+type limiter struct {
+	slots chan struct{}
+
+	mu      sync.Mutex
+	backoff map[string]time.Time // backoffKey -> don't send before
+}
+
+// shared is created on first use rather than at init, so settings loaded
+// from the config file in main still apply.
+var shared = sync.OnceValue(func() *limiter { return newLimiter(maxConcurrent()) })
+
+// maxConcurrent reads TERMIFLOW_MAX_CONCURRENT (default 2).
+func maxConcurrent() int {
+	if n, err := strconv.Atoi(os.Getenv("TERMIFLOW_MAX_CONCURRENT")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxConcurrent
+}
+
+func newLimiter(n int) *limiter {
+	return &limiter{
+		slots:   make(chan struct{}, n),
+		backoff: make(map[string]time.Time),
+	}
+}
+
+type limitedTransport struct {
+	base    http.RoundTripper
+	limiter *limiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := t.limiter
+	key := backoffKey(req)
+
+	if wait := l.backoffFor(key); wait > 0 {
+		if wait > maxBackoffWait {
+			return nil, fmt.Errorf("%s is rate limiting requests; retry in %s", key, wait.Round(time.Second))
+		}
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := sync.OnceFunc(func() { <-l.slots })
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if d, limited := RetryAfter(resp); limited {
+		l.setBackoff(key, d)
+	}
+	// Hold the slot until the body is consumed
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// backoffKey is what a backoff applies to: the host, except that GitHub's
+// search API has its own, much lower, rate limit and backs off separately
+// so a search that hit it doesn't hold up listing issues.
+func backoffKey(req *http.Request) string {
+	if strings.HasPrefix(req.URL.Path, "/search/") {
+		return req.URL.Host + "/search"
+	}
+	return req.URL.Host
+}
+
+func (l *limiter) backoffFor(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Until(l.backoff[key])
+}
+
+func (l *limiter) setBackoff(key string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.backoff[key] = time.Now().Add(d)
+}
+
+// RetryAfter reports whether resp is a rate-limit response and how long to
+// wait, using Retry-After or GitHub's X-RateLimit-Reset when present.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+	default:
+		return 0, false
+	}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t), true
+		}
+	}
+	if v := resp.Header.Get("X-RateLimit-Reset"); v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Until(time.Unix(epoch, 0)), true
+		}
+	}
+	return defaultBackoff, true
+}
+
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	b.release()
+	return b.ReadCloser.Close()
+}
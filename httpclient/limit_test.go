@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func limitedClient(n int) *http.Client {
+	return &http.Client{Transport: &limitedTransport{base: http.DefaultTransport, limiter: newLimiter(n)}}
+}
+
+func get(t *testing.T, c *http.Client, url string) (*http.Response, error) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp, nil
+}
+
+func TestLimitedTransportCapsInFlight(t *testing.T) {
+	const limit, requests = 2, 10
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c := limitedClient(limit)
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := get(t, c, srv.URL); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > limit {
+		t.Errorf("peak in-flight requests = %d, want at most %d", p, limit)
+	}
+	if p := peak.Load(); p < limit {
+		t.Errorf("peak in-flight requests = %d, want the limit %d reached", p, limit)
+	}
+}
+
+func TestLimitedTransportHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	c := limitedClient(2)
+	resp, err := get(t, c, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("first status = %d, want 429", resp.StatusCode)
+	}
+
+	start := time.Now()
+	resp, err = get(t, c, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took < 900*time.Millisecond {
+		t.Errorf("request after a 429 went out after %s, want it held for the 1s Retry-After", took)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("second status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestLimitedTransportFailsFastOnLongBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := limitedClient(2)
+	if _, err := get(t, c, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	_, err := get(t, c, srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "rate limiting") {
+		t.Fatalf("err = %v, want a rate limiting error", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server saw %d requests, want 1: the backoff should stop the second", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  map[string]string
+		want    time.Duration
+		limited bool
+	}{
+		{"ok", 200, nil, 0, false},
+		{"plain 403", 403, nil, 0, false},
+		{"429 seconds", 429, map[string]string{"Retry-After": "7"}, 7 * time.Second, true},
+		{"503 default", 503, nil, defaultBackoff, true},
+		{"github exhausted", 403, map[string]string{"X-RateLimit-Remaining": "0"}, defaultBackoff, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+			got, limited := RetryAfter(resp)
+			if got != tt.want || limited != tt.limited {
+				t.Errorf("RetryAfter = %s, %v; want %s, %v", got, limited, tt.want, tt.limited)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package jiraapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"termiflow/config"
+)
+
+// Search endpoints. Atlassian Cloud is retiring the offset-paginated
+// /search in favor of /search/jql, which pages with nextPageToken.
+// Server/Data Center only has the legacy one.
+const (
+	SearchLegacy = "legacy"
+	SearchJQL    = "jql"
+)
+
+// SearchAPI returns JIRA_SEARCH_API ("legacy" or "jql") when set, otherwise
+// the enhanced search for *.atlassian.net and the legacy search elsewhere.
+func SearchAPI(baseURL string) string {
+	switch v := strings.ToLower(os.Getenv("JIRA_SEARCH_API")); v {
+	case SearchLegacy, SearchJQL:
+		return v
+	}
+	if u, err := url.Parse(baseURL); err == nil && strings.HasSuffix(u.Hostname(), ".atlassian.net") {
+		return SearchJQL
+	}
+	return SearchLegacy
+}
+
+// SearchPath returns the REST path for the given search API.
+func SearchPath(api string) string {
+	if api == SearchJQL {
+		return "/rest/api/3/search/jql"
+	}
+	return "/rest/api/3/search"
+}
+
+// IssuePath returns the REST path for a single issue.
+func IssuePath(key string) string {
+	return "/rest/api/3/issue/" + url.PathEscape(key)
+}
+
+// Authentication modes for JIRA_AUTH. Basic sends JIRA_EMAIL:JIRA_TOKEN;
+// bearer sends JIRA_TOKEN as a Personal Access Token (Data Center, OAuth).
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+)
+
+// AuthMode returns JIRA_AUTH, defaulting to basic.
+func AuthMode() string {
+	if strings.EqualFold(os.Getenv("JIRA_AUTH"), AuthBearer) {
+		return AuthBearer
+	}
+	return AuthBasic
+}
+
+// Configured reports whether the Jira settings needed for the current auth
+// mode are present. JIRA_EMAIL is only required for basic auth.
+func Configured() bool {
+	if os.Getenv("JIRA_URL") == "" || config.Secret("JIRA_TOKEN") == "" {
+		return false
+	}
+	return AuthMode() == AuthBearer || os.Getenv("JIRA_EMAIL") != ""
+}
+
+// AuthHeader returns the Authorization header value for the given mode.
+func AuthHeader(mode, email, token string) string {
+	if mode == AuthBearer {
+		return "Bearer " + token
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(email+":"+token))
+}
+
+// SetAuth sets the Authorization header from the environment.
+func SetAuth(req *http.Request) {
+	req.Header.Set("Authorization", AuthHeader(AuthMode(), os.Getenv("JIRA_EMAIL"), config.Secret("JIRA_TOKEN")))
+}
+
+// StatusError explains a failed response. Authentication, permission and
+// not-found failures say what to check; anything else reports the status.
+// Either way the error messages Jira sent, such as a JQL syntax error, are
+// appended.
+func StatusError(resp *http.Response) error {
+	var msg string
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		msg = "Authentication failed — check JIRA_EMAIL/JIRA_TOKEN"
+		if AuthMode() == AuthBearer {
+			msg = "Authentication failed — check JIRA_TOKEN (a Personal Access Token, since JIRA_AUTH=bearer)"
+		}
+	case http.StatusForbidden:
+		msg = "Permission denied for this query"
+	case http.StatusNotFound:
+		return errors.New("Jira URL or endpoint not found — check JIRA_URL")
+	default:
+		msg = "API Error: " + resp.Status
+	}
+	if details := errorMessages(resp.Body); details != "" {
+		msg += ": " + details
+	}
+	return errors.New(msg)
+}
+
+// maxErrorBody bounds how much of an error response is read for messages.
+const maxErrorBody = 16 << 10
+
+// errorMessages returns the messages in a Jira error body
+// ({"errorMessages": [...], "errors": {"field": "..."}}), or "".
+func errorMessages(body io.Reader) string {
+	var e struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(body, maxErrorBody))
+	if json.Unmarshal(data, &e) != nil {
+		return ""
+	}
+	msgs := e.ErrorMessages
+	for _, field := range slices.Sorted(maps.Keys(e.Errors)) {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", field, e.Errors[field]))
+	}
+	return strings.Join(msgs, "; ")
+}
@@ -0,0 +1,108 @@
+// Package keyring keeps secrets in the OS keychain instead of the
+// environment or the config file: the macOS Keychain, the Secret Service
+// (GNOME Keyring, KWallet) on Linux and the Credential Manager on Windows,
+// through go-keyring. Where none answers every call returns ErrUnavailable
+// and callers fall back to env/config.
+package keyring
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	gokeyring "github.com/zalando/go-keyring"
+)
+
+// service groups this app's entries in the keychain; the account is the
+// setting name, e.g. GITHUB_TOKEN.
+const service = "termiflow"
+
+const (
+	// probeTimeout bounds the one lookup that decides whether a keyring is
+	// there at all, so a missing Secret Service daemon costs a second once.
+	probeTimeout = time.Second
+	// timeout bounds later calls, which may wait on an unlock prompt.
+	timeout = 5 * time.Second
+)
+
+var (
+	ErrUnavailable = errors.New("no OS keyring available (needs the macOS Keychain, a Secret Service such as GNOME Keyring, or the Windows Credential Manager)")
+	ErrNotFound    = errors.New("not in the keyring")
+	errTimeout     = errors.New("the OS keyring did not answer")
+)
+
+var (
+	probeOnce sync.Once
+	available bool
+)
+
+// Available reports whether secrets can be stored in the keyring. The
+// first call probes it with a lookup; the answer is kept for the run.
+func Available() bool {
+	probeOnce.Do(func() {
+		_, err := call(probeTimeout, func() (string, error) {
+			return gokeyring.Get(service, "probe")
+		})
+		available = err == nil || errors.Is(err, ErrNotFound)
+	})
+	return available
+}
+
+// Get returns the secret stored under name.
+func Get(name string) (string, error) {
+	if !Available() {
+		return "", ErrUnavailable
+	}
+	return call(timeout, func() (string, error) {
+		return gokeyring.Get(service, name)
+	})
+}
+
+// Set stores value under name, replacing any earlier one.
+func Set(name, value string) error {
+	if !Available() {
+		return ErrUnavailable
+	}
+	_, err := call(timeout, func() (string, error) {
+		return "", gokeyring.Set(service, name, value)
+	})
+	return err
+}
+
+// Delete removes the secret stored under name, if any.
+func Delete(name string) error {
+	if !Available() {
+		return ErrUnavailable
+	}
+	_, err := call(timeout, func() (string, error) {
+		return "", gokeyring.Delete(service, name)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// call runs a keyring operation, giving up after d: go-keyring can't be
+// cancelled, and a locked keychain may otherwise block indefinitely. The
+// abandoned call finishes in the background.
+func call(d time.Duration, op func() (string, error)) (string, error) {
+	type result struct {
+		value string
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := op()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		if errors.Is(r.err, gokeyring.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		return r.value, r.err
+	case <-time.After(d):
+		return "", errTimeout
+	}
+}
@@ -1,18 +1,110 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
 
+	"termiflow/config"
 	"termiflow/ui"
+	"termiflow/version"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
-	p := tea.NewProgram(ui.New(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	tab := flag.String("tab", "", "open on this tab (dashboard, shell, jira, github, chat or last)")
+	repo := flag.String("repo", "", "GitHub repos to show, as owner/name[,owner/name...]")
+	jql := flag.String("jql", "", "Jira search to show instead of your own issues")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(version.String())
+		return
+	}
+
+	if err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not read config file: %v\n", err)
+	}
+
+	// e.g. `termiflow jira --jql "project = OPS"`: print and exit
+	if flag.NArg() > 0 {
+		os.Exit(runHeadless(flag.Args()))
+	}
+
+	if *tab != "" && !ui.KnownTab(*tab) {
+		fatalf("unknown tab %q", *tab)
+	}
+	if *jql != "" && *tab == "" {
+		*tab = "jira"
+	}
+	overrides := []struct{ name, value string }{
+		{"TERMIFLOW_DEFAULT_TAB", *tab},
+		{"GITHUB_REPOS", *repo},
+		{"JIRA_ALL_JQL", *jql},
+	}
+	for _, o := range overrides {
+		if o.value == "" {
+			continue
+		}
+		if err := config.Override(o.name, o.value); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	m := ui.New()
+	if *jql != "" {
+		m = m.ShowAllJira()
+	}
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	if stdinPiped() {
+		// e.g. `echo "explain this error" | termiflow`: keys come from the
+		// terminal instead
+		text, err := io.ReadAll(io.LimitReader(os.Stdin, maxStdin))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not read stdin: %v\n", err)
+		}
+		m = m.Seed(string(text))
+		opts = append(opts, tea.WithInputTTY())
+	}
+
+	p := tea.NewProgram(m, opts...)
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
+	if m, ok := final.(ui.Model); ok && m.ShutdownErr() != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save state: %v\n", m.ShutdownErr())
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage:
+  termiflow [flags]                  open the TUI
+  termiflow jira [--jql QUERY]       print Jira issues and exit
+  termiflow github [--repo REPOS]    print GitHub issues and exit
+  termiflow keyring set|delete NAME  store or remove a secret in the OS keyring
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+// fatalf reports a usage error and exits.
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "termiflow: "+format+"\n", args...)
+	os.Exit(2)
+}
+
+// maxStdin caps how much piped input is read to seed the chat or shell.
+const maxStdin = 256 << 10
+
+// stdinPiped reports whether stdin is a pipe or file rather than a terminal.
+func stdinPiped() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice == 0
 }
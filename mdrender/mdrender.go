@@ -0,0 +1,39 @@
+// Package mdrender renders Markdown for the terminal with glamour, in the
+// style named by GLAMOUR_STYLE. The chat and the issue detail views share
+// it.
+package mdrender
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+)
+
+// Style returns GLAMOUR_STYLE, defaulting to the dark style.
+func Style() string {
+	if style := os.Getenv("GLAMOUR_STYLE"); style != "" {
+		return style
+	}
+	return styles.DarkStyle // auto-detection queries the terminal, which fights Bubble Tea
+}
+
+// NewRenderer builds a renderer for the current style, wrapping at width.
+func NewRenderer(width int) (*glamour.TermRenderer, error) {
+	return glamour.NewTermRenderer(glamour.WithStandardStyle(Style()), glamour.WithWordWrap(width))
+}
+
+// Render renders content once, falling back to the raw text if glamour
+// fails. Callers that re-render often should keep a NewRenderer instead.
+func Render(content string, width int) string {
+	r, err := NewRenderer(width)
+	if err != nil {
+		return content
+	}
+	out, err := r.Render(content)
+	if err != nil {
+		return content
+	}
+	return strings.Trim(out, "\n")
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"os"
+	"time"
+
+	"termiflow/quiet"
+
+	"github.com/gen2brain/beeep"
+)
+
+func init() {
+	beeep.AppName = "TermiFlow"
+}
+
+// defaultLongCommand is how long a shell command must run before its
+// completion is worth a notification.
+const defaultLongCommand = 10 * time.Second
+
+// Enabled reports whether desktop notifications are on (TERMIFLOW_NOTIFY=1).
+func Enabled() bool {
+	return os.Getenv("TERMIFLOW_NOTIFY") == "1"
+}
+
+// LongCommand returns TERMIFLOW_NOTIFY_AFTER, the runtime after which a
+// command finishing in the background triggers a notification.
+func LongCommand() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("TERMIFLOW_NOTIFY_AFTER")); err == nil && d >= 0 {
+		return d
+	}
+	return defaultLongCommand
+}
+
+// Send shows a desktop notification in the background when enabled and
+// outside quiet hours. Failures (e.g. no notification daemon) are ignored.
+func Send(title, body string) {
+	if !Enabled() || quiet.At(time.Now()) {
+		return
+	}
+	go func() { _ = beeep.Notify(title, body, "") }()
+}
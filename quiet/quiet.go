@@ -0,0 +1,40 @@
+// Package quiet reads TERMIFLOW_QUIET_HOURS, a local-time window like
+// "18:00-09:00" during which auto-refresh and desktop notifications pause.
+package quiet
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// At reports whether now falls in the quiet hours. A window may run past
+// midnight; unset or invalid means never.
+func At(now time.Time) bool {
+	from, to, err := ParseRange(os.Getenv("TERMIFLOW_QUIET_HOURS"))
+	if err != nil {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// ParseRange parses "HH:MM-HH:MM" into minutes since midnight. The end is
+// exclusive, so a range must not start and end at the same minute.
+func ParseRange(v string) (from, to int, err error) {
+	start, end, found := strings.Cut(v, "-")
+	s, err1 := time.Parse("15:04", strings.TrimSpace(start))
+	e, err2 := time.Parse("15:04", strings.TrimSpace(end))
+	if !found || err1 != nil || err2 != nil {
+		return 0, 0, errors.New("must be a time range like 18:00-09:00")
+	}
+	from, to = s.Hour()*60+s.Minute(), e.Hour()*60+e.Minute()
+	if from == to {
+		return 0, 0, errors.New("must start and end at different times")
+	}
+	return from, to, nil
+}
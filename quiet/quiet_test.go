@@ -0,0 +1,63 @@
+package quiet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAt(t *testing.T) {
+	at := func(clock string) time.Time {
+		t, _ := time.ParseInLocation("15:04", clock, time.Local)
+		return t
+	}
+	tests := []struct {
+		name  string
+		hours string
+		now   string
+		want  bool
+	}{
+		{"same day, inside", "12:00-14:00", "13:30", true},
+		{"same day, at the start", "12:00-14:00", "12:00", true},
+		{"same day, at the end", "12:00-14:00", "14:00", false},
+		{"same day, before", "12:00-14:00", "11:59", false},
+		{"past midnight, evening", "18:00-09:00", "23:00", true},
+		{"past midnight, early morning", "18:00-09:00", "03:15", true},
+		{"past midnight, at the end", "18:00-09:00", "09:00", false},
+		{"past midnight, daytime", "18:00-09:00", "12:00", false},
+		{"spaces", " 18:00 - 09:00 ", "20:00", true},
+		{"from equals to", "09:00-09:00", "09:00", false},
+		{"unset", "", "03:00", false},
+		{"no dash", "18:00", "20:00", false},
+		{"not a time", "6pm-9am", "20:00", false},
+		{"out of range", "25:00-09:00", "03:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERMIFLOW_QUIET_HOURS", tt.hours)
+			if got := At(at(tt.now)); got != tt.want {
+				t.Errorf("At(%s) with %q = %v, want %v", tt.now, tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		v        string
+		from, to int
+		ok       bool
+	}{
+		{"18:00-09:00", 18 * 60, 9 * 60, true},
+		{"00:00-23:59", 0, 23*60 + 59, true},
+		{"9:05-10:00", 9*60 + 5, 10 * 60, true},
+		{"09:00-09:00", 0, 0, false},
+		{"09:00", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		from, to, err := ParseRange(tt.v)
+		if (err == nil) != tt.ok || from != tt.from || to != tt.to {
+			t.Errorf("ParseRange(%q) = %d, %d, %v; want %d, %d, ok %v", tt.v, from, to, err, tt.from, tt.to, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package refresh
+
+import "context"
+
+// Inflight tracks a model's latest background fetch, so starting another
+// or quitting cancels it and its late result can be told apart from the
+// current one. Models hold it by pointer so every copy shares it; it is
+// only used from Update, so it needs no locking.
+type Inflight struct {
+	id     int
+	cancel context.CancelFunc
+}
+
+// Start cancels the previous fetch and returns the context and id of a new
+// one.
+func (f *Inflight) Start() (context.Context, int) {
+	f.Cancel()
+	ctx, cancel := context.WithCancel(context.Background())
+	f.id++
+	f.cancel = cancel
+	return ctx, f.id
+}
+
+// Current reports whether id belongs to the latest fetch.
+func (f *Inflight) Current(id int) bool { return id == f.id }
+
+// Cancel stops the latest fetch, if it is still running.
+func (f *Inflight) Cancel() {
+	if f.cancel != nil {
+		f.cancel()
+	}
+}
@@ -0,0 +1,67 @@
+package refresh
+
+import (
+	"time"
+
+	"termiflow/quiet"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Poller runs a tab's auto-refresh (TERMIFLOW_REFRESH_INTERVAL). There is
+// one chain of ticks per tab: each poll's result schedules the next, backed
+// off after failures. Like Inflight, models hold it by pointer and use it
+// only from Update.
+type Poller struct {
+	polling  bool // the fetch in flight is an auto-refresh
+	failures int  // consecutive failed polls, for backoff
+}
+
+// TickMsg asks the Poller that scheduled it to poll. Every tab sees every
+// tick, so each checks it's its own.
+type TickMsg struct{ p *Poller }
+
+// Start schedules the first tick, or returns nil when auto-refresh is off.
+func (p *Poller) Start() tea.Cmd { return p.schedule() }
+
+func (p *Poller) schedule() tea.Cmd {
+	interval := Interval()
+	if interval == 0 {
+		return nil
+	}
+	return tea.Tick(Delay(interval, p.failures), func(time.Time) tea.Msg { return TickMsg{p} })
+}
+
+// Tick handles a tick: it ignores other Pollers' ticks, and while busy (a
+// fetch is already in flight) or in quiet hours it just waits for the next
+// one. Otherwise the fetch fetch starts is marked as a poll.
+func (p *Poller) Tick(msg TickMsg, busy bool, fetch func() tea.Cmd) tea.Cmd {
+	if msg.p != p {
+		return nil
+	}
+	if busy || quiet.At(time.Now()) {
+		return p.schedule()
+	}
+	p.polling = true
+	return fetch()
+}
+
+// Polling reports whether the fetch in flight is an auto-refresh, so its
+// result can announce new issues or keep the last good list on failure.
+func (p *Poller) Polling() bool { return p.polling }
+
+// Done records a fetch's result and, if it was a poll, schedules the next
+// one: at the interval after a success, backed off after a
+// failure. It returns nil for fetches the user started.
+func (p *Poller) Done(err error) tea.Cmd {
+	if !p.polling {
+		return nil
+	}
+	p.polling = false
+	if err != nil {
+		p.failures++
+	} else {
+		p.failures = 0
+	}
+	return p.schedule()
+}
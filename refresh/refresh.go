@@ -0,0 +1,38 @@
+package refresh
+
+import (
+	"os"
+	"time"
+)
+
+// MinInterval keeps polling from hammering the APIs when the interval is
+// set very low.
+const MinInterval = 15 * time.Second
+
+// maxBackoff caps how far failures stretch the interval.
+const maxBackoff = 8
+
+// Interval returns TERMIFLOW_REFRESH_INTERVAL (a Go duration such as "5m"),
+// or 0 when auto-refresh is off: unset, invalid or not positive.
+func Interval() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("TERMIFLOW_REFRESH_INTERVAL"))
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return max(d, MinInterval)
+}
+
+// Delay is how long to wait before the next poll after failures
+// consecutive failed ones: the interval doubles with each failure, so
+// polling backs off while offline or rate-limited and recovers once a
+// fetch works again.
+func Delay(interval time.Duration, failures int) time.Duration {
+	factor := 1
+	for range failures {
+		if factor >= maxBackoff {
+			break
+		}
+		factor *= 2
+	}
+	return interval * time.Duration(factor)
+}
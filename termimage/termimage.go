@@ -0,0 +1,84 @@
+package termimage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/ansi/iterm2"
+	"github.com/charmbracelet/x/ansi/kitty"
+)
+
+// Protocol is an inline image protocol supported by the terminal.
+type Protocol int
+
+const (
+	None Protocol = iota
+	ITerm
+	Kitty
+)
+
+// Detect returns the image protocol to use. Inline images are opt-in
+// (TERMIFLOW_INLINE_IMAGES=1) because support varies widely, and multiplexers
+// like tmux often swallow the escape sequences.
+func Detect() Protocol {
+	if os.Getenv("TERMIFLOW_INLINE_IMAGES") != "1" {
+		return None
+	}
+	switch {
+	case os.Getenv("TERM") == "xterm-kitty", os.Getenv("KITTY_WINDOW_ID") != "":
+		return Kitty
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app", os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return ITerm
+	}
+	return None
+}
+
+// Render returns the escape sequence drawing the image cols cells wide, or
+// a text placeholder when the terminal can't show it.
+func Render(data []byte, mimeType string, cols int) string {
+	switch Detect() {
+	case ITerm:
+		return ansi.ITerm2(iterm2.File{
+			Size:    int64(len(data)),
+			Width:   iterm2.Cells(cols),
+			Inline:  true,
+			Content: []byte(base64.StdEncoding.EncodeToString(data)),
+		})
+	case Kitty:
+		if s, err := encodeKitty(data, cols); err == nil {
+			return s
+		}
+	}
+	return Placeholder(data, mimeType)
+}
+
+// Placeholder describes an image that isn't rendered.
+func Placeholder(data []byte, mimeType string) string {
+	return fmt.Sprintf("[image: %s, %d KB]", mimeType, (len(data)+1023)/1024)
+}
+
+// encodeKitty decodes the image and re-sends it as PNG over the kitty
+// graphics protocol, which only takes raw pixels or PNG.
+func encodeKitty(data []byte, cols int) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = kitty.EncodeGraphics(&sb, img, &kitty.Options{
+		Action:       kitty.TransmitAndPut,
+		Transmission: kitty.Direct,
+		Format:       kitty.PNG,
+		Columns:      cols,
+		Chunk:        true,
+	})
+	return sb.String(), err
+}
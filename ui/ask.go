@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"termiflow/ui/github"
+	"termiflow/ui/jira"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// askBodyLimit keeps the issue body short enough to fit the chat input.
+const askBodyLimit = 600
+
+// askAboutSelection pre-fills the chat with the issue highlighted on the
+// Jira or GitHub tab and switches to it. It reports false when there's no
+// issue to ask about, so the key goes to the list as usual.
+func (m *Model) askAboutSelection() (tea.Cmd, bool) {
+	var prompt string
+	switch m.state {
+	case viewJira:
+		issue, ok := m.jira.SelectedIssue()
+		if !ok || m.jira.Filtering() || m.jira.Viewing() {
+			return nil, false
+		}
+		prompt = jiraPrompt(issue)
+	case viewGitHub:
+		issue, ok := m.github.SelectedIssue()
+		if !ok || m.github.Filtering() || m.github.Editing() {
+			return nil, false
+		}
+		prompt = githubPrompt(issue)
+	default:
+		return nil, false
+	}
+	m.state = viewChat
+	return m.chat.Prefill(prompt), true
+}
+
+func jiraPrompt(issue jira.JiraIssue) string {
+	return issuePrompt(
+		fmt.Sprintf("Jira issue %s: %s", issue.Key, issue.Fields.Summary),
+		issue.Fields.Status.Name,
+		issue.DescriptionText(),
+	)
+}
+
+func githubPrompt(issue github.GitHubIssue) string {
+	kind := "issue"
+	if issue.PullRequest != nil {
+		kind = "pull request"
+	}
+	return issuePrompt(
+		fmt.Sprintf("GitHub %s %s#%d: %s", kind, issue.Repo, issue.Number, issue.Title),
+		issue.State,
+		issue.Body,
+	)
+}
+
+// issuePrompt leaves the cursor after a trailing question line so the user
+// can finish it or just press enter.
+func issuePrompt(heading, status, body string) string {
+	var sb strings.Builder
+	sb.WriteString(heading + "\n")
+	sb.WriteString("Status: " + status + "\n")
+	if body = strings.TrimSpace(body); body != "" {
+		if r := []rune(body); len(r) > askBodyLimit {
+			body = string(r[:askBodyLimit]) + "…"
+		}
+		sb.WriteString(body + "\n")
+	}
+	sb.WriteString("\nCan you summarize this and suggest next steps?")
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+package chat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"termiflow/storage"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Response Cache --
+
+// Answers to identical prompts in an identical conversation are reused when
+// TERMIFLOW_CHAT_CACHE=1, saving quota during demos and debugging.
+const (
+	cacheDir        = "chat_cache"
+	cacheTTL        = 24 * time.Hour
+	cacheMaxEntries = 200
+)
+
+func cacheEnabled() bool {
+	return os.Getenv("TERMIFLOW_CHAT_CACHE") == "1"
+}
+
+type cacheEntry struct {
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
+// cacheKey hashes everything that shapes the answer: model, system prompt,
+// the conversation so far and the new message.
+func cacheKey(model string, system *genai.Content, history []*genai.Content, msg string) string {
+	data, _ := json.Marshal(struct {
+		Model   string
+		System  *genai.Content
+		History []*genai.Content
+		Message string
+	}{model, system, history, msg})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func cachePath(key string) (string, error) {
+	dir, err := storage.Path(cacheDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+".json"), nil
+}
+
+// loadCached returns the cached answer for key if it hasn't expired.
+func loadCached(key string) (string, bool) {
+	path, err := cachePath(key)
+	if err != nil {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || time.Since(e.Created) > cacheTTL {
+		return "", false
+	}
+	return e.Text, true
+}
+
+func storeCached(key, text string) error {
+	path, err := cachePath(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cacheEntry{Text: text, Created: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := storage.WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+	return pruneCache(filepath.Dir(path))
+}
+
+// pruneCache deletes expired entries and then the oldest ones beyond
+// cacheMaxEntries.
+func pruneCache(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	type file struct {
+		path string
+		mod  time.Time
+	}
+	var files []file
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if time.Since(info.ModTime()) > cacheTTL {
+			os.Remove(path)
+			continue
+		}
+		files = append(files, file{path, info.ModTime()})
+	}
+	if len(files) <= cacheMaxEntries {
+		return nil
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.Before(files[j].mod) })
+	for _, f := range files[:len(files)-cacheMaxEntries] {
+		os.Remove(f.path)
+	}
+	return nil
+}
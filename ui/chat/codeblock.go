@@ -0,0 +1,180 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// -- Code Blocks --
+
+type codeBlock struct {
+	lang string // info string after the fence, e.g. "go"
+	code string
+}
+
+// extensions maps common fence languages to file extensions for the
+// default filename.
+var extensions = map[string]string{
+	"go": ".go", "golang": ".go",
+	"python": ".py", "py": ".py",
+	"javascript": ".js", "js": ".js",
+	"typescript": ".ts", "ts": ".ts",
+	"tsx": ".tsx", "jsx": ".jsx",
+	"rust": ".rs", "rs": ".rs",
+	"java": ".java", "kotlin": ".kt",
+	"c": ".c", "cpp": ".cpp", "c++": ".cpp",
+	"ruby": ".rb", "rb": ".rb",
+	"bash": ".sh", "sh": ".sh", "shell": ".sh", "zsh": ".sh",
+	"json": ".json", "yaml": ".yaml", "yml": ".yaml", "toml": ".toml",
+	"html": ".html", "css": ".css", "sql": ".sql",
+	"markdown": ".md", "md": ".md",
+	"dockerfile": ".dockerfile", "makefile": ".mk",
+}
+
+// codeBlocks extracts the fenced (``` or ~~~) blocks from markdown. An
+// unterminated block runs to the end of the text.
+func codeBlocks(markdown string) []codeBlock {
+	var blocks []codeBlock
+	var cur *codeBlock
+	var fence string
+	var body []string
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if cur == nil {
+			for _, f := range []string{"```", "~~~"} {
+				if strings.HasPrefix(trimmed, f) {
+					info := strings.Fields(strings.TrimLeft(trimmed, f[:1]))
+					cur = &codeBlock{}
+					if len(info) > 0 {
+						cur.lang = strings.ToLower(info[0])
+					}
+					fence, body = f, nil
+					break
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			cur.code = strings.Join(body, "\n") + "\n"
+			blocks = append(blocks, *cur)
+			cur = nil
+			continue
+		}
+		body = append(body, line)
+	}
+	if cur != nil && len(body) > 0 {
+		cur.code = strings.Join(body, "\n") + "\n"
+		blocks = append(blocks, *cur)
+	}
+	return blocks
+}
+
+// defaultName is snippet plus an extension matching the block's language.
+func (b codeBlock) defaultName() string {
+	ext, ok := extensions[b.lang]
+	if !ok {
+		ext = ".txt"
+	}
+	return "snippet" + ext
+}
+
+// lastAnswer returns the latest message from Gemini.
+func (m Model) lastAnswer() (string, bool) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role == "model" {
+			return m.messages[i].Content, true
+		}
+	}
+	return "", false
+}
+
+// saveCodeBlock handles `/save [n] [file]`: with several blocks and no n it
+// lists them; otherwise it writes block n (default 1) to file (default
+// snippet.<ext> in the current directory). Existing files are never
+// overwritten.
+func (m Model) saveCodeBlock(args []string) string {
+	answer, ok := m.lastAnswer()
+	if !ok {
+		return "No answer to save code from yet."
+	}
+	blocks := codeBlocks(answer)
+	if len(blocks) == 0 {
+		return "The last answer has no code blocks."
+	}
+
+	n := 1
+	if len(args) > 0 {
+		if i, err := strconv.Atoi(args[0]); err == nil {
+			if i < 1 || i > len(blocks) {
+				return fmt.Sprintf("No code block %d; the last answer has %d.", i, len(blocks))
+			}
+			n, args = i, args[1:]
+		} else if len(blocks) > 1 {
+			return listBlocks(blocks)
+		}
+	} else if len(blocks) > 1 {
+		return listBlocks(blocks)
+	}
+	block := blocks[n-1]
+
+	name := block.defaultName()
+	if len(args) > 0 {
+		name = args[0]
+	}
+	path, err := writeNew(name, block.code)
+	if err != nil {
+		return fmt.Sprintf("Could not save code block: %v", err)
+	}
+	return fmt.Sprintf("Saved code block %d to %s", n, path)
+}
+
+func listBlocks(blocks []codeBlock) string {
+	var sb strings.Builder
+	sb.WriteString("The last answer has several code blocks:\n")
+	for i, b := range blocks {
+		first, _, _ := strings.Cut(strings.TrimSpace(b.code), "\n")
+		lang := b.lang
+		if lang == "" {
+			lang = "text"
+		}
+		fmt.Fprintf(&sb, "  %d. %s, %d lines: %s\n", i+1, lang, strings.Count(b.code, "\n"), ansi.Truncate(first, 50, "…"))
+	}
+	sb.WriteString("Save one with /save <n> [file]")
+	return sb.String()
+}
+
+// writeNew creates name (expanding a leading ~) with code and returns its
+// absolute path. It fails rather than replace an existing file.
+func writeNew(name, code string) (string, error) {
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		name = filepath.Join(home, rest)
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("%s already exists; pass another filename", path)
+		}
+		return "", err
+	}
+	if _, err := f.WriteString(code); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,139 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// -- Collapsing Messages --
+
+var (
+	toolStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#808080"))
+	pickStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFAF00"))
+)
+
+// selectMessage moves the selection to the previous (delta < 0) or next
+// (delta > 0) model or tool message and scrolls it into view.
+func (m *Model) selectMessage(delta int) {
+	i := m.selected
+	if i < 0 {
+		i = len(m.messages) // start from the bottom
+	}
+	for i += delta; i >= 0 && i < len(m.messages); i += delta {
+		if role := m.messages[i].Role; role == "model" || role == "tool" {
+			m.selected = i
+			m.ref = -1
+			m.copied = ""
+			m.renderViewport()
+			m.viewport.SetYOffset(m.offsets[i])
+			return
+		}
+	}
+}
+
+// toggleCollapsed collapses or expands the selected message, or the latest
+// model message when nothing is selected. Tool output starts collapsed; a
+// truncated answer is first expanded to its full text.
+func (m *Model) toggleCollapsed() {
+	i := m.selected
+	if i < 0 {
+		for j := len(m.messages) - 1; j >= 0; j-- {
+			if m.messages[j].Role == "model" {
+				i = j
+				break
+			}
+		}
+	}
+	if i < 0 {
+		return
+	}
+	switch {
+	case m.messages[i].Role == "tool":
+		m.messages[i].Expanded = !m.messages[i].Expanded
+	case !m.messages[i].Collapsed && truncated(m.messages[i]):
+		m.messages[i].Full = true
+	default:
+		m.messages[i].Collapsed = !m.messages[i].Collapsed
+	}
+	m.renderViewport()
+	m.viewport.SetYOffset(m.offsets[i])
+}
+
+// collapsedSummary shows the first non-empty line of a long message.
+func collapsedSummary(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line + " … [expand]"
+		}
+	}
+	return "… [expand]"
+}
+
+// toolOutput records a tool's result, summarized as e.g.
+// "[get_jira_issues → 5 issues]".
+func toolOutput(name string, res map[string]any) Message {
+	data, _ := json.MarshalIndent(res, "", "  ")
+	return Message{
+		Role:    "tool",
+		Content: string(data),
+		Summary: fmt.Sprintf("[%s → %s]", name, summarize(res)),
+		Items:   toolItems(res),
+	}
+}
+
+// toolNote records a tool call that produced nothing to expand.
+func toolNote(summary string) Message {
+	return Message{Role: "tool", Summary: summary}
+}
+
+// summarize counts the first list in a result, falling back to its HTTP
+// status or number of fields.
+func summarize(res map[string]any) string {
+	keys := make([]string, 0, len(res))
+	for k := range res {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v := reflect.ValueOf(res[k]); v.Kind() == reflect.Slice {
+			return fmt.Sprintf("%d %s", v.Len(), k)
+		}
+	}
+	if status, ok := res["status"]; ok {
+		return fmt.Sprintf("HTTP %v", status)
+	}
+	return fmt.Sprintf("%d fields", len(res))
+}
+
+// renderTool shows tool output as its summary line; expanded, a result
+// with a list shows one line per entry, pick marking the highlighted one,
+// and anything else the raw JSON.
+func (m Model) renderTool(msg Message, pick int) string {
+	switch {
+	case msg.Content == "":
+		return toolStyle.Render(msg.Summary) + "\n"
+	case !msg.Expanded:
+		return toolStyle.Render("▸ "+msg.Summary) + "\n"
+	case len(msg.Items) == 0:
+		return toolStyle.Render("▾ "+msg.Summary) + "\n" + m.renderBody(msg.Content) + "\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(toolStyle.Render("▾ "+msg.Summary) + "\n")
+	for i, it := range msg.Items {
+		line := "  " + it.ID
+		if it.Title != "" {
+			line += "  " + it.Title
+		}
+		if i == pick {
+			line = pickStyle.Render("▶" + line[1:])
+		}
+		sb.WriteString(ansi.Truncate(line, m.viewport.Width, "…") + "\n")
+	}
+	return sb.String()
+}
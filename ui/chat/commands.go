@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"strings"
+
+	"termiflow/demo"
+	"termiflow/version"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Slash Commands --
+
+// handleCommand runs a local "/command" typed into the chat input instead of
+// sending it to Gemini. It reports whether the input was a command, plus any
+// follow-up work.
+func (m *Model) handleCommand(input string) (bool, tea.Cmd) {
+	if !strings.HasPrefix(input, "/") {
+		return false, nil
+	}
+	var cmd tea.Cmd
+	fields := strings.Fields(input)
+	switch fields[0] {
+	case "/version":
+		m.messages = append(m.messages, Message{Role: "system", Content: version.String()})
+	case "/save":
+		m.messages = append(m.messages, Message{Role: "system", Content: m.saveCodeBlock(fields[1:])})
+	case "/setup":
+		if !needsSetup() {
+			m.messages = append(m.messages, Message{Role: "system", Content: "Gemini is already configured; /status checks that it works."})
+			break
+		}
+		cmd = m.startKeyEntry()
+	case "/clear":
+		m.messages = append(m.messages, Message{Role: "system", Content: m.clearConversation()})
+	case "/sessions":
+		if note := m.openSwitcher(); note != "" {
+			m.messages = append(m.messages, Message{Role: "system", Content: note})
+		}
+	case "/config":
+		m.messages = append(m.messages, Message{Role: "system", Content: m.configCommand(fields[1:])})
+	case "/reload":
+		var note string
+		note, cmd = m.reload()
+		m.messages = append(m.messages, Message{Role: "system", Content: note})
+	case "/status":
+		m.messages = append(m.messages, Message{Role: "system", Content: "Checking connections…"})
+		var clientErr error
+		if !demo.Enabled() {
+			clientErr = m.ensureClient()
+		}
+		cmd = m.checkStatus(clientErr)
+	default:
+		return false, nil
+	}
+	m.updateViewport()
+	m.textarea.Reset()
+	return true, cmd
+}
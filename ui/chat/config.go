@@ -0,0 +1,120 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"termiflow/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Settings --
+
+// configCommand handles `/config` (list settings), `/config set NAME value`
+// and `/config unset NAME`. Edits are saved to the config file and applied
+// right away where the app rereads them.
+func (m *Model) configCommand(args []string) string {
+	if len(args) == 0 {
+		return formatConfig(config.List())
+	}
+	switch {
+	case args[0] == "set" && len(args) >= 3:
+		return m.setConfig(args[1], strings.Join(args[2:], " "))
+	case args[0] == "unset" && len(args) == 2:
+		return m.setConfig(args[1], "")
+	}
+	return "Usage: /config, /config set NAME value, /config unset NAME"
+}
+
+func (m *Model) setConfig(name, value string) string {
+	s, err := config.Set(name, value)
+	if err != nil {
+		return fmt.Sprintf("Not saved: %v", err)
+	}
+	switch {
+	case s.Name == "GEMINI_MODEL":
+		m.sess.pick("") // an explicit model replaces one picked with alt+m
+	case strings.HasPrefix(s.Name, "GEMINI_") && s.Name != "GEMINI_KEEP_TURNS",
+		strings.HasPrefix(s.Name, "GOOGLE_CLOUD_"):
+		m.sess.invalidate()
+	case s.Name == "GLAMOUR_STYLE", s.Name == "TERMIFLOW_CHAT_MAX_CHARS":
+		m.renderViewport()
+	}
+	action := "Set " + s.Name
+	if value == "" {
+		action = "Unset " + s.Name
+	}
+	if s.Restart {
+		return action + " (takes effect after a restart)"
+	}
+	return action
+}
+
+// ReloadedMsg announces that /reload re-read the config, so the root can
+// hand the changes to every tab.
+type ReloadedMsg struct {
+	Changed config.Changes
+}
+
+// reload handles /reload: it re-reads the config file and keyring and
+// reports what changed.
+func (m *Model) reload() (string, tea.Cmd) {
+	changed, err := config.Reload()
+	if err != nil {
+		return fmt.Sprintf("Could not reload config: %v", err), nil
+	}
+	if len(changed) == 0 {
+		return "Config reloaded; nothing changed.", nil
+	}
+	var names []string
+	for _, s := range config.Settings { // in README order
+		if changed[s.Name] {
+			names = append(names, s.Name)
+		}
+	}
+	note := "Config reloaded; changed " + strings.Join(names, ", ")
+	return note, func() tea.Msg { return ReloadedMsg{changed} }
+}
+
+// ApplyConfig picks up settings changed by /reload. A new Gemini key, model
+// or backend reconnects before the next turn, keeping the conversation.
+func (m *Model) ApplyConfig(changed config.Changes) tea.Cmd {
+	if changed.Any("GEMINI_", "GOOGLE_CLOUD_") {
+		m.sess.invalidate()
+	}
+	if changed["GEMINI_MODEL"] {
+		m.sess.pick("")
+	}
+	m.approveTools = os.Getenv("GEMINI_TOOL_APPROVAL") == "1"
+	m.banner = needsSetup()
+	m.textarea.Placeholder = askPlaceholder
+	if m.banner {
+		m.textarea.Placeholder = setupPlaceholder
+	}
+	if changed["GLAMOUR_STYLE"] || changed["TERMIFLOW_CHAT_MAX_CHARS"] {
+		m.renderViewport()
+	}
+	return nil
+}
+
+func formatConfig(entries []config.Entry) string {
+	var sb strings.Builder
+	for _, e := range entries {
+		value := e.Value
+		if value == "" {
+			value = "(unset)"
+		}
+		fmt.Fprintf(&sb, "%s = %s", e.Name, value)
+		if e.Source != "" {
+			fmt.Fprintf(&sb, "  [%s]", e.Source)
+		}
+		sb.WriteString("\n")
+	}
+	if path := config.ProjectFile(); path != "" {
+		fmt.Fprintf(&sb, "[project] settings come from %s and win over the config file.\n", path)
+	}
+	sb.WriteString("Change with /config set NAME value or /config unset NAME; secrets can only be set in the environment or keyring. After editing the config file, /reload applies it.")
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Context Window Handling --
+
+const defaultKeepTurns = 10
+
+// keepTurns is how many recent user/model exchanges survive a trim.
+// Override with GEMINI_KEEP_TURNS.
+func keepTurns() int {
+	if v, err := strconv.Atoi(os.Getenv("GEMINI_KEEP_TURNS")); err == nil && v > 0 {
+		return v
+	}
+	return defaultKeepTurns
+}
+
+// isContextLengthErr reports whether the API rejected the request because the
+// conversation no longer fits in the model's context window.
+func isContextLengthErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "exceeds the maximum number of tokens") ||
+		strings.Contains(s, "input token count") ||
+		strings.Contains(s, "context length") ||
+		strings.Contains(s, "context window")
+}
+
+// trimHistory drops the oldest turns from the session so only the last
+// `turns` exchanges remain. The system instruction lives on the model, not in
+// History, so it is always kept. It also removes the trailing user message
+// left behind by the failed SendMessage, so the caller can resend it.
+// Returns the number of history entries dropped (excluding that message).
+func trimHistory(cs *genai.ChatSession, turns int) int {
+	h := cs.History
+	if n := len(h); n > 0 && h[n-1].Role == "user" {
+		h = h[:n-1]
+	}
+
+	keep := turns * 2
+	if len(h) <= keep {
+		cs.History = h
+		return 0
+	}
+
+	start := len(h) - keep
+	// History must start with a user turn, and not one answering a function
+	// call that was trimmed away
+	for start < len(h) && !isUserText(h[start]) {
+		start++
+	}
+
+	cs.History = append([]*genai.Content(nil), h[start:]...)
+	return start
+}
+
+// isUserText reports whether c is a message the user typed. Function
+// responses go back to Gemini with the user role too.
+func isUserText(c *genai.Content) bool {
+	if c.Role != "user" {
+		return false
+	}
+	text := false
+	for _, p := range c.Parts {
+		switch p.(type) {
+		case genai.FunctionResponse, *genai.FunctionResponse:
+			return false
+		case genai.Text:
+			text = true
+		}
+	}
+	return text
+}
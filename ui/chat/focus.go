@@ -0,0 +1,55 @@
+package chat
+
+import (
+	"termiflow/ui/focus"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Focus --
+
+var hintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#808080"))
+
+// parts are the tab's focusable parts in tab order: the message being
+// written and the conversation, navigated without typing. Only the focused
+// one gets keys.
+func (m *Model) parts() []focus.Focusable {
+	return []focus.Focusable{&m.textarea, &m.conversation}
+}
+
+// focusInput gives focus back to the message being written.
+func (m *Model) focusInput() tea.Cmd {
+	return focus.Set(&m.textarea, m.parts()...)
+}
+
+// updateConversation handles keys while the conversation is focused: the
+// input's scroll keys work without clearing it first, j/k scroll by line and
+// esc/enter return to the input. n/N pick an entry of a tool result or an
+// issue reference in an answer, which y/u copy (ID or link), o opens in the
+// browser and t shows in its tab.
+// It reports false for keys that should go through the usual handling
+// (arrows, alt+↑/↓, ctrl+o…).
+func (m *Model) updateConversation(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch key := msg.String(); key {
+	case "j":
+		m.viewport.ScrollDown(1)
+	case "k":
+		m.viewport.ScrollUp(1)
+	case "esc", "enter", "i":
+		return m.focusInput(), true
+	case "n":
+		m.stepRef(1)
+	case "N":
+		m.stepRef(-1)
+	case "o":
+		m.openPick()
+	case "y", "u":
+		m.copyPick(key == "u")
+	case "t":
+		return m.showPick(), true
+	default:
+		return nil, m.scroll(key)
+	}
+	return nil, true
+}
@@ -0,0 +1,70 @@
+package chat
+
+import (
+	"strings"
+
+	"termiflow/mdrender"
+	"termiflow/storage"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// -- Markdown Rendering --
+
+// settingsFile keeps chat display preferences between runs.
+const settingsFile = "chat_settings.json"
+
+type chatSettings struct {
+	RawMarkdown bool `json:"raw_markdown"`
+}
+
+func loadSettings() chatSettings {
+	var s chatSettings
+	_ = storage.LoadJSON(settingsFile, &s) // unreadable: defaults
+	return s
+}
+
+type mdKey struct {
+	content string
+	width   int
+}
+
+// markdown renders model answers with glamour, memoizing by content and
+// width since the whole conversation is re-rendered on every change.
+type markdown struct {
+	width    int
+	style    string // GLAMOUR_STYLE the renderer was built with
+	renderer *glamour.TermRenderer
+	cache    map[mdKey]string
+}
+
+func (md *markdown) render(content string, width int) string {
+	key := mdKey{content, width}
+	style := mdrender.Style()
+	if md.renderer == nil || md.width != width || md.style != style {
+		r, err := mdrender.NewRenderer(width)
+		if err != nil {
+			return content
+		}
+		md.renderer, md.width, md.style = r, width, style
+		md.cache = map[mdKey]string{}
+	}
+	if out, ok := md.cache[key]; ok {
+		return out
+	}
+	out, err := md.renderer.Render(content)
+	if err != nil {
+		return content
+	}
+	out = strings.Trim(out, "\n")
+	md.cache[key] = out
+	return out
+}
+
+// toggleRawMarkdown flips between rendered and raw answers and remembers
+// the choice.
+func (m *Model) toggleRawMarkdown() error {
+	m.settings.RawMarkdown = !m.settings.RawMarkdown
+	m.renderViewport()
+	return storage.SaveJSON(settingsFile, m.settings)
+}
@@ -3,38 +3,110 @@ package chat
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-
 	"os"
 	"strings"
+	"time"
+
+	"termiflow/demo"
+	"termiflow/gemini"
+	"termiflow/hooks"
+	"termiflow/storage"
+	"termiflow/termimage"
+	"termiflow/ui/focus"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+)
+
+var (
+	userLabelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#00AFFF")).Bold(true)
+	modelLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#AF87FF")).Bold(true)
+	systemStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	bodyStyle       = lipgloss.NewStyle().PaddingLeft(2)
 )
 
 type Message struct {
-	Role    string
-	Content string
+	Role      string `json:"role"`
+	Content   string `json:"content"`
+	Collapsed bool   `json:"collapsed,omitempty"` // show only the first line
+	Cached    bool   `json:"cached,omitempty"`    // answered from the response cache
+	Full      bool   `json:"full,omitempty"`      // show all of an answer over maxChars
+
+	// Images Gemini sent with an answer, drawn below the text by
+	// renderViewport and never part of Content or the chat history.
+	Images []Image `json:"images,omitempty"`
+
+	// Tool output (role "tool"): Content is the raw JSON, shown only when
+	// expanded under the one-line Summary.
+	Summary  string     `json:"summary,omitempty"`
+	Expanded bool       `json:"expanded,omitempty"`
+	Items    []toolItem `json:"items,omitempty"` // entries of the result's list
+}
+
+// Image is an inline image from a model response.
+type Image struct {
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data"`
 }
 
+// historyFile holds the conversation between runs, inside ~/.termiflow.
+const historyFile = "chat_history.json"
+
 type Model struct {
-	viewport    viewport.Model
-	textarea    textarea.Model
-	messages    []Message
-	client      *genai.Client
-	model       *genai.GenerativeModel
-	chatSession *genai.ChatSession
-	err         error
-	initialized bool
+	viewport viewport.Model
+	textarea textarea.Model
+	messages []Message
+	sess     *session // shared by every copy of the model; see session
+	err      error
+	lastErr  string // latest Gemini or setup error, for copying
+
+	// Tool approval (GEMINI_TOOL_APPROVAL=1)
+	approveTools bool
+	allowedTools map[string]bool // answered "always" this session
+	pending      *toolApprovalMsg
+	restTools    map[string]restTool // endpoints from ~/.termiflow/tools.json
+
+	conversation focus.Pane // the conversation has focus instead of the input
+	selected     int        // index of the selected model or tool message, -1 for none
+	offsets      []int      // first viewport line of each message
+	ref          int        // highlighted pick (n/N) in the target message, -1 for none
+	copied       string     // what y/u last copied, shown in the hint
+
+	settings chatSettings
+	md       *markdown
+
+	// Missing API key: a banner replaces the error on every send
+	banner    bool // setup banner showing
+	keyEntry  bool // /setup prompt replaces the input
+	keyInput  textinput.Model
+	keyToSave string // /setup key awaiting the answer to "save to the keyring?"
+
+	switcher *list.Model // saved conversations (/sessions), nil when closed
+
+	// Rate limits: a send waiting to be retried and how many waits in a row
+	retry   *pendingRetry
+	retries int
+	retryID int
+
+	took time.Duration // how long Gemini took over the last answer
 }
 
+const (
+	askPlaceholder   = "Ask Gemini..."
+	setupPlaceholder = "Ask Gemini... (type /setup to add an API key)"
+)
+
 func New() Model {
 	ta := textarea.New()
-	ta.Placeholder = "Ask Gemini... (Ensure GEMINI_API_KEY is set)"
+	ta.Placeholder = askPlaceholder
 	ta.Focus()
 
 	ta.Prompt = "┃ "
@@ -45,16 +117,60 @@ func New() Model {
 
 	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
 	ta.ShowLineNumbers = false
-	ta.KeyMap.InsertNewline.SetEnabled(false) // Enter sends message
+	ta.KeyMap.InsertNewline.SetEnabled(false)              // Enter sends message
+	ta.KeyMap.TransposeCharacterBackward.SetEnabled(false) // ctrl+t toggles markdown
+
+	// Sized by the first SetSize; the welcome is rendered then, at the real width
+	vp := viewport.New(0, 0)
+	vp.KeyMap = scrollKeys()
+
+	m := Model{
+		textarea:     ta,
+		viewport:     vp,
+		messages:     []Message{},
+		approveTools: os.Getenv("GEMINI_TOOL_APPROVAL") == "1",
+		allowedTools: map[string]bool{},
+		selected:     -1,
+		ref:          -1,
+		settings:     loadSettings(),
+		md:           &markdown{},
+		sess:         &session{},
+		keyInput:     newKeyInput(),
+		banner:       needsSetup(),
+	}
+	if m.banner {
+		m.textarea.Placeholder = setupPlaceholder
+	}
+	if err := storage.LoadJSON(historyFile, &m.messages); err != nil {
+		m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Could not load chat history: %v", err)})
+	}
+	// Without this Gemini would see the restored messages for the first time
+	m.rebuildSession(m.messages)
+	var err error
+	if m.restTools, err = loadRESTTools(); err != nil {
+		m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Could not load %s: %v", restToolsFile, err)})
+	}
+	m.updateViewport()
+	return m
+}
 
-	vp := viewport.New(50, 10)
-	vp.SetContent("Welcome to The Bridge Chat! 🤖\nType a message and press Enter to chat with Gemini.\n")
+// Shutdown persists the conversation so it survives a restart.
+func (m Model) Shutdown() error {
+	return errors.Join(storage.SaveJSON(historyFile, m.messages), m.sess.close())
+}
 
-	return Model{
-		textarea: ta,
-		viewport: vp,
-		messages: []Message{},
-	}
+func (m Model) Messages() []Message { return m.messages }
+
+// LastError returns the most recent error shown in the conversation.
+func (m Model) LastError() string { return m.lastErr }
+
+// Prefill replaces the input with text for the user to edit and send. The
+// input grows to fit, since the text may be a pasted log.
+func (m *Model) Prefill(text string) tea.Cmd {
+	m.textarea.CharLimit = max(m.textarea.CharLimit, len([]rune(text)))
+	m.textarea.MaxHeight = max(m.textarea.MaxHeight, strings.Count(text, "\n")+1)
+	m.textarea.SetValue(text)
+	return m.focusInput()
 }
 
 func (m Model) Init() tea.Cmd {
@@ -62,117 +178,251 @@ func (m Model) Init() tea.Cmd {
 }
 
 type errMsg error
-type responseMsg string
+type responseMsg struct {
+	before  []Message // tool output and model text from earlier rounds
+	text    string
+	images  []Image
+	dropped int           // history entries trimmed to fit the context window
+	cached  bool          // served from the response cache
+	took    time.Duration // waiting on Gemini, summed over tool rounds
+}
 
-func (m Model) getKey() string {
-	return os.Getenv("GEMINI_API_KEY")
+// toolApprovalMsg pauses the conversation until the user allows or denies
+// the requested function calls.
+type toolApprovalMsg struct {
+	before  []Message
+	text    string // model text preceding the calls
+	calls   []genai.FunctionCall
+	dropped int
+	round   int
 }
 
-func (m *Model) ensureClient() error {
-	if m.client != nil {
-		return nil
-	}
-	ctx := context.Background()
-	apiKey := m.getKey()
-	if apiKey == "" {
-		return fmt.Errorf("GEMINI_API_KEY environment variable not set")
-	}
-	c, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return err
-	}
-	m.client = c
-	modelName := os.Getenv("GEMINI_MODEL")
-	if modelName == "" {
-		modelName = "gemini-1.5-flash-002" // Latest stable flash
-	}
-	m.model = c.GenerativeModel(modelName)
-	m.model.Tools = tools
-	m.chatSession = m.model.StartChat()
-	m.initialized = true
-	return nil
+// ensureClient connects to Gemini on first use.
+func (m Model) ensureClient() error {
+	return m.sess.open(m.restTools)
 }
 
 func (m Model) sendMessage(msg string) tea.Cmd {
 	return func() tea.Msg {
-		// Because we can't easily modify the model in the command closure if it's not a pointer,
-		// and we handled client init lazily.
-		// However, m is captured by value.
-		// We have to rely on m.client being set.
-		// But wait, the client init must happen before or we need a way to pass it.
-		// Actually, let's just create a new client or handle state differently?
-		// No, we should rely on the Update loop to handle init or check it here.
-		// But safely, we should probably do the client init in Update or passed via pointer.
-		// Bubble Tea models are value types.
-		// So `m.client` will be nil in the command if it was nil when `sendMessage` was called.
-
-		// We'll reload the client here if needed for this closure instance.
 		ctx := context.Background()
-		apiKey := os.Getenv("GEMINI_API_KEY")
-		if apiKey == "" {
-			return errMsg(fmt.Errorf("GEMINI_API_KEY not set"))
+		m.sess.mu.Lock()
+		defer m.sess.mu.Unlock()
+		if err := m.sess.prepare(); err != nil {
+			return errMsg(gemini.FriendlyError(err))
+		}
+		m.sess.spent = 0
+		cs := m.sess.chat
+		if cs == nil {
+			return errMsg(fmt.Errorf("Chat session not initialized"))
 		}
 
-		// For the sake of simplicity in this stateless command, let's create a client if we don't carry state well.
-		// BUT `chatSession` holds history. We NEED to persist `chatSession`.
-		// Since `chatSession` is a pointer in the struct, changes to the struct's pointer fields persist across copies if they point to the same object.
-		// So as long as `m.chatSession` was initialized in `Update` *before* `sendMessage` returned this cmd, we are good.
-		// But in `Update`, we call `m.sendMessage(userMsg)`.
+		var key string
+		if cacheEnabled() {
+			key = cacheKey(m.sess.name, m.sess.model.SystemInstruction, cs.History, msg)
+			if text, ok := loadCached(key); ok {
+				// Record the exchange so the conversation continues normally
+				cs.History = append(cs.History,
+					genai.NewUserContent(genai.Text(msg)),
+					&genai.Content{Role: "model", Parts: []genai.Part{genai.Text(text)}})
+				return responseMsg{text: text, cached: true}
+			}
+		}
 
-		return func() tea.Msg {
-			// This inner closure executes asynchronously.
-			// It needs access to chatSession.
-			// If we access m.chatSession, it uses the captured m.
-			if m.chatSession == nil {
-				return errMsg(fmt.Errorf("Chat session not initialized"))
+		resp, err := m.sess.send(ctx, genai.Text(msg))
+		dropped := 0
+		if isContextLengthErr(err) {
+			// Conversation outgrew the context window: drop the oldest turns and retry once
+			if dropped = trimHistory(cs, keepTurns()); dropped > 0 {
+				resp, err = m.sess.send(ctx, genai.Text(msg))
 			}
+		}
+		if err != nil {
+			err = gemini.FriendlyError(err)
+			var rl *gemini.RateLimitError
+			if errors.As(err, &rl) {
+				dropFailedTurn(cs)
+				return rateLimitedMsg{text: msg, err: rl}
+			}
+			var blocked *genai.BlockedError
+			if errors.As(err, &blocked) {
+				dropFailedTurn(cs) // or every later turn would be refused too
+			}
+			return errMsg(err)
+		}
 
-			resp, err := m.chatSession.SendMessage(ctx, genai.Text(msg))
+		// Only plain answers are cached; tool results depend on live data
+		if text, calls, err := splitParts(resp); key != "" && err == nil && len(calls) == 0 {
+			_ = storeCached(key, text) // best effort
+		}
+
+		return m.sess.timed(m.processResponse(ctx, resp, dropped, 1))
+	}
+}
+
+// maxToolRounds caps call -> response -> call loops within one user turn.
+const maxToolRounds = 5
+
+// processResponse turns a model response into a message for Update. Function
+// calls are executed (or sent for approval) and their results are sent back
+// to the session so the conversation stays valid. The caller holds
+// m.sess.mu.
+func (m Model) processResponse(ctx context.Context, resp *genai.GenerateContentResponse, dropped, round int) tea.Msg {
+	text, calls, err := splitParts(resp)
+	if err != nil {
+		return errMsg(err)
+	}
+
+	if len(calls) == 0 {
+		return responseMsg{text: text, images: responseImages(resp), dropped: dropped}
+	}
+	if round > maxToolRounds {
+		return responseMsg{text: text + "\n[Stopped after too many tool calls]\n", dropped: dropped}
+	}
+	if m.needsApproval(calls) {
+		return toolApprovalMsg{text: text, calls: calls, dropped: dropped, round: round}
+	}
+	return m.runTools(ctx, text, calls, true, dropped, round)
+}
+
+// imageCols is how many terminal cells wide inline images are drawn.
+const imageCols = 40
+
+// splitParts separates the first candidate's text from its function calls.
+func splitParts(resp *genai.GenerateContentResponse) (string, []genai.FunctionCall, error) {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", nil, fmt.Errorf("empty response")
+	}
+
+	var text strings.Builder
+	var calls []genai.FunctionCall
+	for _, part := range resp.Candidates[0].Content.Parts {
+		switch p := part.(type) {
+		case genai.Text:
+			text.WriteString(string(p))
+		case genai.FunctionCall:
+			calls = append(calls, p)
+		}
+	}
+	return text.String(), calls, nil
+}
+
+// responseImages returns the images in the first candidate, kept apart from
+// its text so they never reach the history, the cache or the clipboard.
+func responseImages(resp *genai.GenerateContentResponse) []Image {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return nil
+	}
+	var images []Image
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if p, ok := part.(genai.Blob); ok && strings.HasPrefix(p.MIMEType, "image/") {
+			images = append(images, Image{MIMEType: p.MIMEType, Data: p.Data})
+		}
+	}
+	return images
+}
+
+// runTools executes (or, when denied, skips) the calls, shows their output
+// and hands the results back to Gemini for its follow-up answer. The caller
+// holds m.sess.mu.
+func (m Model) runTools(ctx context.Context, prefix string, calls []genai.FunctionCall, approved bool, dropped, round int) tea.Msg {
+	var before []Message
+	if strings.TrimSpace(prefix) != "" {
+		before = append(before, Message{Role: "model", Content: prefix})
+	}
+
+	var results []genai.Part
+	for _, call := range calls {
+		var result map[string]any
+		fn, ok := m.toolFunc(call.Name)
+		switch {
+		case !approved:
+			before = append(before, toolNote(fmt.Sprintf("[Tool %s denied]", call.Name)))
+			result = map[string]any{"error": "The user declined to run this tool."}
+		case !ok:
+			before = append(before, toolNote(fmt.Sprintf("[Unknown tool: %s]", call.Name)))
+			result = map[string]any{"error": "unknown tool"}
+		default:
+			res, err := fn(call.Args)
 			if err != nil {
-				return errMsg(err)
-			}
-
-			if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-				return errMsg(fmt.Errorf("empty response"))
-			}
-
-			// Handle response parts (Text or FunctionCall)
-			var responseBuilder strings.Builder
-			for _, part := range resp.Candidates[0].Content.Parts {
-				switch p := part.(type) {
-				case genai.Text:
-					responseBuilder.WriteString(string(p))
-				case genai.FunctionCall:
-					// Execute function
-					if fn, ok := toolFunctions[p.Name]; ok {
-						// For now, we ignore arguments as our simple tools don't use them or use env vars
-						// In a real app, unmarshal p.Args
-						res, err := fn()
-						if err != nil {
-							responseBuilder.WriteString(fmt.Sprintf("\n[Error executing %s: %v]\n", p.Name, err))
-						} else {
-							// Send result back to model
-							// Note: To truly have a conversation loop with tools, we need to send the function response
-							// back to the chat session. This simple implementation just shows the result.
-							// To do it right:
-
-							// We need to send this back to the model.
-							// This requires a more complex loop in `sendMessage` or `Update`.
-							// For this simple version, let's just print the JSON result to the chat.
-							jsonRes, _ := json.MarshalIndent(res, "", "  ")
-							responseBuilder.WriteString(fmt.Sprintf("\n[Tool %s Output]:\n%s\n", p.Name, string(jsonRes)))
-
-							// Ideally: Send function response back to Gemini to get a natural language summary.
-							// But that requires chaining commands. Let's start with this.
-						}
-					} else {
-						responseBuilder.WriteString(fmt.Sprintf("\n[Unknown tool: %s]\n", p.Name))
-					}
-				}
+				before = append(before, toolNote(fmt.Sprintf("[Error executing %s: %v]", call.Name, err)))
+				result = map[string]any{"error": err.Error()}
+			} else {
+				before = append(before, toolOutput(call.Name, res))
+				result = res
 			}
+		}
+		results = append(results, genai.FunctionResponse{Name: call.Name, Response: result})
+	}
 
-			return responseMsg(responseBuilder.String())
-		}()
+	resp, err := m.sess.send(ctx, results...)
+	if err != nil {
+		return responseMsg{before: before, text: fmt.Sprintf("[Error sending tool results: %v]", gemini.FriendlyError(err)), dropped: dropped}
+	}
+
+	switch next := m.processResponse(ctx, resp, dropped, round+1).(type) {
+	case responseMsg:
+		next.before = append(before, next.before...)
+		return next
+	case toolApprovalMsg:
+		next.before = append(before, next.before...)
+		return next
+	default:
+		// Keep the tool output even if the follow-up failed
+		return responseMsg{before: before, dropped: dropped}
+	}
+}
+
+// needsApproval reports whether any call must be confirmed by the user.
+func (m Model) needsApproval(calls []genai.FunctionCall) bool {
+	if !m.approveTools {
+		return false
+	}
+	for _, c := range calls {
+		if !m.allowedTools[c.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTools continues the conversation once the user answered the
+// approval prompt.
+func (m Model) resolveTools(req toolApprovalMsg, approved bool) tea.Cmd {
+	return func() tea.Msg {
+		m.sess.mu.Lock()
+		defer m.sess.mu.Unlock()
+		if m.sess.chat == nil {
+			return errMsg(fmt.Errorf("Chat session not initialized"))
+		}
+		m.sess.spent = 0
+		return m.sess.timed(m.runTools(context.Background(), "", req.calls, approved, 0, req.round))
+	}
+}
+
+func formatCall(c genai.FunctionCall) string {
+	if len(c.Args) == 0 {
+		return c.Name + "()"
+	}
+	args, _ := json.Marshal(c.Args)
+	return fmt.Sprintf("%s(%s)", c.Name, args)
+}
+
+// demoResponse replies with the next scripted answer from the demo fixtures.
+func (m Model) demoResponse() tea.Cmd {
+	turn := 0
+	for _, msg := range m.messages {
+		if msg.Role == "user" {
+			turn++
+		}
+	}
+	return func() tea.Msg {
+		var script struct {
+			Responses []string `json:"responses"`
+		}
+		if err := json.Unmarshal(demo.Fixture("chat"), &script); err != nil {
+			return errMsg(err)
+		}
+		return responseMsg{text: script.Responses[(turn-1)%len(script.Responses)]}
 	}
 }
 
@@ -182,11 +432,66 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		vpCmd tea.Cmd
 	)
 
+	// While a tool call awaits approval, keys answer the prompt
+	if key, ok := msg.(tea.KeyMsg); ok && m.pending != nil {
+		return m.answerApproval(key.String())
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && m.keyToSave != "" {
+		return m.answerKeyring(key.String())
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && m.keyEntry {
+		return m.updateKeyEntry(key)
+	}
+	if m.switcher != nil {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSwitcher(key)
+		}
+		*m.switcher, _ = m.switcher.Update(msg) // expire status messages
+	}
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if s := key.String(); s == "tab" || s == "shift+tab" {
+			return m, focus.Cycle(s == "shift+tab", m.parts()...)
+		}
+		if key.String() == "alt+m" { // before the input, which would type an m
+			m.messages = append(m.messages, Message{Role: "system", Content: m.cycleModel()})
+			m.updateViewport()
+			return m, nil
+		}
+		if m.conversation.Focused() {
+			if cmd, handled := m.updateConversation(key); handled {
+				return m, cmd
+			}
+		}
+	}
+
 	m.textarea, tiCmd = m.textarea.Update(msg)
 	m.viewport, vpCmd = m.viewport.Update(msg)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		switch msg.String() {
+		case "alt+up":
+			m.selectMessage(-1)
+		case "alt+down":
+			m.selectMessage(1)
+		case "ctrl+o":
+			m.toggleCollapsed()
+		case "esc":
+			m.banner = false
+			if m.cancelRetry() {
+				m.messages = append(m.messages, Message{Role: "system", Content: "[Retry cancelled]"})
+				m.updateViewport()
+			}
+		case "ctrl+l":
+			m.messages = append(m.messages, Message{Role: "system", Content: m.clearConversation()})
+			m.updateViewport()
+		case "ctrl+t":
+			if err := m.toggleRawMarkdown(); err != nil {
+				m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Could not save chat settings: %v", err)})
+				m.updateViewport()
+			}
+		}
+
 		switch msg.Type {
 		case tea.KeyEnter:
 			if m.textarea.Value() == "" {
@@ -194,9 +499,27 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 			userMsg := m.textarea.Value()
 
+			if ok, cmd := m.handleCommand(userMsg); ok {
+				return m, tea.Batch(tiCmd, vpCmd, cmd)
+			}
+
+			if demo.Enabled() {
+				m.messages = append(m.messages, Message{Role: "user", Content: userMsg})
+				m.updateViewport()
+				m.textarea.Reset()
+				return m, tea.Batch(tiCmd, vpCmd, m.demoResponse())
+			}
+
+			// Without a key, point at the banner instead of failing each send
+			if needsSetup() {
+				m.banner = true
+				return m, tea.Batch(tiCmd, vpCmd)
+			}
+
 			// Init client if needed
 			if err := m.ensureClient(); err != nil {
 				m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Error: %v", err)})
+				m.lastErr = err.Error()
 				m.updateViewport()
 				m.textarea.Reset()
 				return m, nil
@@ -205,47 +528,239 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.messages = append(m.messages, Message{Role: "user", Content: userMsg})
 			m.updateViewport()
 			m.textarea.Reset()
+			m.cancelRetry() // a new message replaces one waiting out a rate limit
+			m.retries = 0
 
 			return m, tea.Batch(tiCmd, vpCmd, m.sendMessage(userMsg))
 		}
 	case responseMsg:
-		m.messages = append(m.messages, Message{Role: "model", Content: string(msg)})
+		m.retries = 0
+		if !msg.cached {
+			m.took = msg.took
+		}
+		m.noteDropped(msg.dropped)
+		m.messages = append(m.messages, msg.before...)
+		if msg.text != "" || len(msg.images) > 0 || len(msg.before) == 0 {
+			m.messages = append(m.messages, Message{Role: "model", Content: msg.text, Cached: msg.cached, Images: msg.images})
+		}
+		hooks.Fire(hooks.ChatResponse, map[string]any{"text": msg.text, "cached": msg.cached})
+		m.updateViewport()
+	case toolApprovalMsg:
+		m.noteDropped(msg.dropped)
+		m.messages = append(m.messages, msg.before...)
+		if strings.TrimSpace(msg.text) != "" {
+			m.messages = append(m.messages, Message{Role: "model", Content: msg.text})
+		}
+		var names []string
+		for _, c := range msg.calls {
+			names = append(names, formatCall(c))
+		}
+		m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Gemini wants to call %s — allow? (y)es / (n)o / (a)lways", strings.Join(names, ", "))})
+		m.pending = &msg
+		m.updateViewport()
+	case statusMsg:
+		m.messages = append(m.messages, Message{Role: "system", Content: formatStatus(msg.lines)})
 		m.updateViewport()
+	case rateLimitedMsg:
+		return m, tea.Batch(tiCmd, vpCmd, m.rateLimited(msg))
+	case retryTickMsg:
+		return m, tea.Batch(tiCmd, vpCmd, m.tickRetry(msg))
 	case errMsg:
 		m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Error: %v", msg)})
+		m.lastErr = msg.Error()
 		m.updateViewport()
 	}
 
 	return m, tea.Batch(tiCmd, vpCmd)
 }
 
+func (m *Model) noteDropped(n int) {
+	if n > 0 {
+		m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("[Context limit reached: dropped %d older messages from the conversation]", n)})
+	}
+}
+
+func (m Model) answerApproval(key string) (Model, tea.Cmd) {
+	req := *m.pending
+	switch key {
+	case "y":
+		m.messages = append(m.messages, Message{Role: "system", Content: "[Allowed]"})
+	case "a":
+		for _, c := range req.calls {
+			m.allowedTools[c.Name] = true
+		}
+		m.messages = append(m.messages, Message{Role: "system", Content: "[Allowed for this session]"})
+	case "n", "esc":
+		m.pending = nil
+		m.messages = append(m.messages, Message{Role: "system", Content: "[Denied]"})
+		m.updateViewport()
+		return m, m.resolveTools(req, false)
+	default:
+		return m, nil
+	}
+	m.pending = nil
+	m.updateViewport()
+	return m, m.resolveTools(req, true)
+}
+
+// welcome is shown while the conversation is empty.
+const welcome = "Welcome to The Bridge Chat! 🤖\nType a message and press Enter to chat with Gemini."
+
+// updateViewport re-renders the conversation and follows the newest message.
 func (m *Model) updateViewport() {
+	m.selected = -1
+	m.ref = -1
+	m.copied = ""
+	m.renderViewport()
+	if len(m.messages) > 0 {
+		m.viewport.GotoBottom()
+	}
+}
+
+// renderViewport re-renders the conversation, recording where each message
+// starts so it can be scrolled to.
+func (m *Model) renderViewport() {
 	var sb strings.Builder
-	for _, msg := range m.messages {
+	lines := 0
+	m.offsets = m.offsets[:0]
+	for i, msg := range m.messages {
+		m.offsets = append(m.offsets, lines)
+		marker := ""
+		if i == m.selected {
+			marker = "▶ "
+		}
+		var block string
 		if msg.Role == "user" {
-			sb.WriteString(fmt.Sprintf("\nYou: %s\n", msg.Content))
+			block = fmt.Sprintf("\n%s\n%s\n", userLabelStyle.Render("You"), m.renderBody(msg.Content))
 		} else if msg.Role == "model" {
-			sb.WriteString(fmt.Sprintf("Gemini: %s\n", msg.Content))
+			label := "Gemini"
+			if msg.Cached {
+				label += " (cached)"
+			}
+			var body string
+			content, omitted := shownContent(msg)
+			switch {
+			case msg.Collapsed:
+				body = m.renderBody(collapsedSummary(msg.Content))
+				omitted = 0
+			case m.settings.RawMarkdown:
+				body = m.renderBody(content)
+			default:
+				body = m.md.render(content, m.viewport.Width)
+			}
+			if !msg.Collapsed {
+				for _, img := range msg.Images {
+					body += "\n" + termimage.Render(img.Data, img.MIMEType, imageCols)
+				}
+			}
+			if omitted > 0 {
+				body += "\n" + hintStyle.Width(m.viewport.Width).Render(fmt.Sprintf("[response truncated — %d chars omitted • ctrl+o shows all]", omitted))
+			}
+			block = fmt.Sprintf("%s%s\n%s\n", marker, modelLabelStyle.Render(label), body)
+		} else if msg.Role == "tool" {
+			pick := -1
+			if i == m.refTarget() {
+				pick = m.ref
+			}
+			block = marker + m.renderTool(msg, pick)
 		} else {
-			sb.WriteString(fmt.Sprintf("%s\n", msg.Content))
+			block = fmt.Sprintf("%s\n", systemStyle.Width(m.viewport.Width).Render(msg.Content))
 		}
+		sb.WriteString(block)
+		lines += strings.Count(block, "\n")
 	}
-	if len(m.messages) > 0 {
-		m.viewport.SetContent(sb.String())
-		m.viewport.GotoBottom()
+	if len(m.messages) == 0 {
+		sb.WriteString(lipgloss.NewStyle().Width(m.viewport.Width).Render(welcome) + "\n")
 	}
+	m.viewport.SetContent(sb.String())
+}
+
+// renderBody indents message text under its role label, wrapped to the
+// viewport.
+func (m Model) renderBody(content string) string {
+	return bodyStyle.Width(m.viewport.Width).Render(content)
 }
 
 func (m *Model) SetSize(w, h int) {
+	// Re-wrapping moves every line, so note what's in view first
+	atBottom, top := m.viewport.AtBottom(), m.topMessage()
 	m.textarea.SetWidth(w)
 	m.viewport.Width = w
-	m.viewport.Height = h - m.textarea.Height() - 2
+	m.viewport.Height = max(h-m.textarea.Height()-2, 0)
+	if m.switcher != nil {
+		m.switcher.SetSize(m.viewport.Width, m.viewport.Height)
+	}
+	m.renderViewport() // re-wrap to the new width
+	switch {
+	case atBottom:
+		m.viewport.GotoBottom()
+	case top >= 0 && top < len(m.offsets):
+		m.viewport.SetYOffset(m.offsets[top])
+	}
+}
+
+// topMessage is the index of the message at the top of the view, or -1 for
+// an empty conversation.
+func (m Model) topMessage() int {
+	top := -1
+	for i, offset := range m.offsets {
+		if offset > m.viewport.YOffset {
+			break
+		}
+		top = i
+	}
+	return top
 }
 
 func (m Model) View() string {
-	return fmt.Sprintf(
-		"%s\n\n%s",
-		m.viewport.View(),
-		m.textarea.View(),
-	)
+	if m.switcher != nil {
+		return fmt.Sprintf("%s\n\n%s", m.switcher.View(), m.textarea.View())
+	}
+	input := m.textarea.View()
+	if m.keyEntry {
+		input = m.keyInput.View()
+	}
+	gap := hintStyle.Render(m.requestStatus())
+	if m.conversation.Focused() {
+		gap = hintStyle.MaxWidth(m.viewport.Width).Render("conversation focused" + m.refHint() + " • ↑/↓ j/k g/G scroll • tab or esc back to the input")
+	}
+	if !m.banner {
+		return fmt.Sprintf("%s\n%s\n%s", m.viewport.View(), gap, input)
+	}
+	// The banner takes the top of the conversation's space
+	banner := setupBanner(m.viewport.Width)
+	vp := m.viewport
+	vp.Height = max(vp.Height-lipgloss.Height(banner), 1)
+	return fmt.Sprintf("%s\n%s\n%s\n%s", banner, vp.View(), gap, input)
+}
+
+// scroll handles navigation keys that would otherwise edit the input, so
+// only the focused conversation offers them: g/G jump to the top/bottom of
+// the conversation and ctrl+u/ctrl+d move half a page.
+func (m *Model) scroll(key string) bool {
+	switch key {
+	case "g":
+		m.viewport.GotoTop()
+	case "G":
+		m.viewport.GotoBottom()
+	case "ctrl+u":
+		m.viewport.HalfPageUp()
+	case "ctrl+d":
+		m.viewport.HalfPageDown()
+	default:
+		return false
+	}
+	return true
+}
+
+// scrollKeys limits the viewport to keys the input doesn't use. The
+// defaults include letters and readline keys like ctrl+u, which would scroll
+// the conversation while typing.
+func scrollKeys() viewport.KeyMap {
+	return viewport.KeyMap{
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+		Up:       key.NewBinding(key.WithKeys("up")),
+		Down:     key.NewBinding(key.WithKeys("down")),
+	}
 }
@@ -0,0 +1,47 @@
+package chat
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// -- Model Presets (alt+m) --
+
+// presets returns GEMINI_MODELS, the models alt+m cycles through, e.g. a
+// cheap default first and a stronger one to escalate to.
+func presets() []string {
+	var models []string
+	for _, name := range strings.Split(os.Getenv("GEMINI_MODELS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			models = append(models, name)
+		}
+	}
+	return models
+}
+
+// nextPreset is the preset after current, or the first one when current
+// isn't a preset.
+func nextPreset(models []string, current string) string {
+	i := slices.Index(models, current)
+	return models[(i+1)%len(models)]
+}
+
+// cycleModel switches to the next preset for the rest of this run. The
+// pick lives on the session rather than in the config, so the configured
+// model stays the default and /reload doesn't see a change. Like a
+// GEMINI_MODEL change it reconnects before the next turn, keeping the
+// conversation.
+func (m *Model) cycleModel() string {
+	models := presets()
+	if len(models) == 0 {
+		return "No preset models to switch between; try /config set GEMINI_MODELS gemini-2.0-flash,gemini-2.5-pro"
+	}
+	current := m.sess.modelName()
+	next := nextPreset(models, current)
+	if next == current {
+		return "Already using " + next + ", the only preset in GEMINI_MODELS."
+	}
+	m.sess.pick(next)
+	return "Switched to " + next + " (alt+m for the next preset)"
+}
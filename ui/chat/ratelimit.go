@@ -0,0 +1,122 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"termiflow/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Rate Limits --
+
+// rateLimitedMsg reports a send that ran into Gemini's quota. The failed
+// turn was taken back out of the history, so text can be sent again.
+type rateLimitedMsg struct {
+	text string
+	err  *gemini.RateLimitError
+}
+
+// pendingRetry is a send waiting out a rate limit (GEMINI_AUTO_RETRY=1).
+type pendingRetry struct {
+	id   int // matches the retryTickMsg chain counting it down
+	text string
+	at   time.Time
+}
+
+type retryTickMsg struct{ id int }
+
+// Auto-retry gives up after maxAutoRetries waits in a row, since a used-up
+// daily quota won't come back within minutes. When the API suggests no
+// delay, defaultRetryDelay is used.
+const (
+	maxAutoRetries    = 3
+	defaultRetryDelay = time.Minute
+)
+
+func autoRetry() bool { return os.Getenv("GEMINI_AUTO_RETRY") == "1" }
+
+// dropFailedTurn removes the user message a failed SendMessage left at the
+// end of the history.
+func dropFailedTurn(cs *genai.ChatSession) {
+	if n := len(cs.History); n > 0 && cs.History[n-1].Role == "user" {
+		cs.History = cs.History[:n-1]
+	}
+}
+
+// rateLimited reports a rate limit and, with auto-retry on, schedules the
+// send again once the suggested delay has passed.
+func (m *Model) rateLimited(msg rateLimitedMsg) tea.Cmd {
+	m.lastErr = msg.err.Error()
+	if !autoRetry() || m.retries >= maxAutoRetries {
+		m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf("Error: %v", msg.err)})
+		m.updateViewport()
+		return nil
+	}
+	delay := msg.err.Delay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	m.retries++
+	m.retryID++
+	m.retry = &pendingRetry{id: m.retryID, text: msg.text, at: time.Now().Add(delay)}
+	m.messages = append(m.messages, Message{Role: "system", Content: fmt.Sprintf(
+		"Rate limited — retrying in %ds (attempt %d of %d, esc cancels)", secondsLeft(m.retry.at), m.retries, maxAutoRetries)})
+	m.updateViewport()
+	return retryTick(m.retryID)
+}
+
+// retryTick counts down a pending retry once a second, which also keeps
+// the footer's countdown moving.
+func retryTick(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg { return retryTickMsg{id} })
+}
+
+// tickRetry resends the pending message once its delay is up.
+func (m *Model) tickRetry(msg retryTickMsg) tea.Cmd {
+	if m.retry == nil || m.retry.id != msg.id {
+		return nil // cancelled or superseded
+	}
+	if time.Now().Before(m.retry.at) {
+		return retryTick(msg.id)
+	}
+	text := m.retry.text
+	m.retry = nil
+	return m.sendMessage(text)
+}
+
+// cancelRetry drops a pending retry, reporting whether there was one.
+func (m *Model) cancelRetry() bool {
+	if m.retry == nil {
+		return false
+	}
+	m.retry = nil
+	return true
+}
+
+func secondsLeft(at time.Time) int {
+	return int((time.Until(at) + time.Second - 1) / time.Second)
+}
+
+// requestStatus is the footer's note on usage: the model in use, requests
+// sent this run, so users can see a per-minute or per-day limit coming, and
+// any retry counting down.
+func (m Model) requestStatus() string {
+	s := m.sess.modelName()
+	if len(presets()) > 1 {
+		s += " (alt+m switches)"
+	}
+	if n := m.sess.sent.Load(); n > 0 {
+		s += fmt.Sprintf(" • %d Gemini requests this session", n)
+	}
+	if m.took > 0 {
+		s += fmt.Sprintf(" • last answer in %s", m.took.Round(100*time.Millisecond))
+	}
+	if m.retry != nil {
+		s += fmt.Sprintf(" • ⏳ rate limited, retrying in %ds", max(secondsLeft(m.retry.at), 0))
+	}
+	return s
+}
@@ -0,0 +1,342 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"termiflow/browser"
+	"termiflow/clipboard"
+	"termiflow/gitrepo"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Issue References and Tool Items --
+
+// Reference is a Jira key or GitHub issue mentioned in the conversation.
+type Reference struct {
+	Key    string // Jira issue key, "" for GitHub
+	Repo   string // owner/name; "" when written as a bare #123
+	Number int
+}
+
+func (r Reference) String() string {
+	switch {
+	case r.Key != "":
+		return r.Key
+	case r.Repo != "":
+		return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+	}
+	return fmt.Sprintf("#%d", r.Number)
+}
+
+// URL is where the issue lives: JIRA_URL/browse/KEY, or the issue on
+// GitHub.
+func (r Reference) URL() (string, error) {
+	if r.Key != "" {
+		base := os.Getenv("JIRA_URL")
+		if base == "" {
+			return "", fmt.Errorf("JIRA_URL is not set")
+		}
+		return strings.TrimRight(base, "/") + "/browse/" + r.Key, nil
+	}
+	return fmt.Sprintf("https://github.com/%s/issues/%d", r.resolved().Repo, r.Number), nil
+}
+
+// resolved names the repo of a bare #123: the one the chat's GitHub tool
+// reads, which is what the model is talking about.
+func (r Reference) resolved() Reference {
+	if r.Key == "" && r.Repo == "" {
+		r.Repo = gitrepo.Default()
+	}
+	return r
+}
+
+// ShowReferenceMsg asks the root to switch to the reference's tab and
+// show it there. A GitHub reference always names its repo.
+type ShowReferenceMsg struct {
+	Ref Reference
+}
+
+var (
+	jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+	githubPattern  = regexp.MustCompile(`(?:\b([\w.-]+/[\w.-]+))?#([1-9][0-9]*)\b`)
+)
+
+// jiraProjects are the Jira projects of the issues in this conversation's
+// tool results. Only their keys count as references, since the key pattern
+// alone also matches GPT-4, AES-256 or COVID-19.
+func (m Model) jiraProjects() map[string]bool {
+	projects := map[string]bool{}
+	for _, msg := range m.messages {
+		for _, it := range msg.Items {
+			if jiraKeyPattern.MatchString(it.ID) {
+				project, _, _ := strings.Cut(it.ID, "-")
+				projects[project] = true
+			}
+		}
+	}
+	return projects
+}
+
+// references finds the issue identifiers in text, in order and without
+// repeats. Jira keys count only in the given projects.
+func references(text string, projects map[string]bool) []Reference {
+	type match struct {
+		at  int
+		ref Reference
+	}
+	var found []match
+	for _, loc := range jiraKeyPattern.FindAllStringIndex(text, -1) {
+		key := text[loc[0]:loc[1]]
+		project, _, _ := strings.Cut(key, "-")
+		if projects[project] {
+			found = append(found, match{loc[0], Reference{Key: key}})
+		}
+	}
+	for _, loc := range githubPattern.FindAllStringSubmatchIndex(text, -1) {
+		r := Reference{}
+		if loc[2] >= 0 {
+			r.Repo = text[loc[2]:loc[3]]
+		}
+		r.Number, _ = strconv.Atoi(text[loc[4]:loc[5]])
+		found = append(found, match{loc[0], r})
+	}
+	slices.SortStableFunc(found, func(a, b match) int { return a.at - b.at })
+	var refs []Reference
+	for _, f := range found {
+		if !slices.Contains(refs, f.ref) {
+			refs = append(refs, f.ref)
+		}
+	}
+	return refs
+}
+
+// toolItem is one entry of the list in a tool result, such as an issue,
+// so it can be picked out of the output and copied or opened.
+type toolItem struct {
+	ID    string `json:"id"` // issue key, #number or id: what y copies
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"` // what u copies and o opens
+}
+
+// toolItems lists the entries of the first list of objects in a tool
+// result. Jira keys and GitHub numbers link to the issue; other entries use
+// a URL field of their own when they have one.
+func toolItems(res map[string]any) []toolItem {
+	// Round-trip through JSON so typed slices look like any other list
+	data, _ := json.Marshal(res)
+	var generic map[string]any
+	if json.Unmarshal(data, &generic) != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(generic))
+	for k := range generic {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		list, ok := generic[k].([]any)
+		if !ok {
+			continue
+		}
+		var items []toolItem
+		for _, e := range list {
+			if obj, ok := e.(map[string]any); ok {
+				if it, ok := newToolItem(obj); ok {
+					items = append(items, it)
+				}
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+func newToolItem(obj map[string]any) (toolItem, bool) {
+	str := func(names ...string) string {
+		for _, n := range names {
+			switch v := obj[n].(type) {
+			case string:
+				return v
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		return ""
+	}
+	it := toolItem{Title: str("summary", "title", "name"), URL: str("html_url", "web_url", "url")}
+	switch {
+	case str("key") != "":
+		it.ID = str("key")
+		if it.URL == "" {
+			it.URL, _ = Reference{Key: it.ID}.URL()
+		}
+	case str("number") != "":
+		it.ID = "#" + str("number")
+		if n, err := strconv.Atoi(str("number")); err == nil && it.URL == "" {
+			it.URL, _ = Reference{Number: n}.URL()
+		}
+	default:
+		it.ID = str("id")
+	}
+	return it, it.ID != "" || it.URL != ""
+}
+
+// reference reads the item's ID as an issue reference, for t.
+func (it toolItem) reference(projects map[string]bool) (Reference, bool) {
+	refs := references(it.ID, projects)
+	if len(refs) != 1 {
+		return Reference{}, false
+	}
+	return refs[0], true
+}
+
+// refTarget is the message references are taken from: the selected one,
+// or else the latest model or tool message.
+func (m Model) refTarget() int {
+	if m.selected >= 0 {
+		return m.selected
+	}
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if role := m.messages[i].Role; role == "model" || role == "tool" {
+			return i
+		}
+	}
+	return -1
+}
+
+// picks returns what n/N step through in the target message: a tool
+// result's items, or the issue references in an answer.
+func (m Model) picks() []toolItem {
+	i := m.refTarget()
+	if i < 0 {
+		return nil
+	}
+	msg := m.messages[i]
+	if msg.Role == "tool" {
+		return msg.Items
+	}
+	var items []toolItem
+	for _, r := range references(msg.Content, m.jiraProjects()) {
+		url, _ := r.URL()
+		items = append(items, toolItem{ID: r.String(), URL: url})
+	}
+	return items
+}
+
+// stepRef highlights the next (delta > 0) or previous pick in the target
+// message, wrapping around. Tool output is expanded to show it.
+func (m *Model) stepRef(delta int) {
+	n := len(m.picks())
+	if i := m.refTarget(); n > 0 && m.messages[i].Role == "tool" {
+		m.messages[i].Expanded = true
+	}
+	switch {
+	case n == 0:
+		m.ref = -1
+	case m.ref < 0 && delta < 0:
+		m.ref = n - 1
+	case m.ref < 0:
+		m.ref = 0
+	default:
+		m.ref = (m.ref + delta + n) % n
+	}
+	m.copied = ""
+	m.renderViewport()
+}
+
+// picked returns the item highlighted with n/N, if any.
+func (m Model) picked() (toolItem, bool) {
+	items := m.picks()
+	if m.ref < 0 || m.ref >= len(items) {
+		return toolItem{}, false
+	}
+	return items[m.ref], true
+}
+
+// openPick opens the highlighted item in the browser.
+func (m *Model) openPick() {
+	it, ok := m.picked()
+	if !ok {
+		return
+	}
+	if it.URL == "" {
+		m.note(fmt.Sprintf("%s has no link to open", it.ID))
+		return
+	}
+	if err := browser.Open(it.URL); err != nil {
+		m.note(fmt.Sprintf("Could not open %s: %v", it.ID, err))
+	}
+}
+
+// copyPick puts the highlighted item's ID, or with url its link, on the
+// clipboard.
+func (m *Model) copyPick(url bool) {
+	it, ok := m.picked()
+	if !ok {
+		return
+	}
+	text := it.ID
+	if url {
+		text = it.URL
+	}
+	if text == "" {
+		m.note(fmt.Sprintf("%s has no link to copy", it.ID))
+		return
+	}
+	if err := clipboard.Copy(text); err != nil {
+		m.note(fmt.Sprintf("Could not copy: %v", err))
+		return
+	}
+	m.copied = text
+}
+
+// note adds a system message without moving the selection.
+func (m *Model) note(text string) {
+	m.messages = append(m.messages, Message{Role: "system", Content: text})
+	m.renderViewport()
+}
+
+// showPick hands the highlighted issue to the root, which switches to its
+// tab.
+func (m Model) showPick() tea.Cmd {
+	it, ok := m.picked()
+	if !ok {
+		return nil
+	}
+	r, ok := it.reference(m.jiraProjects())
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg { return ShowReferenceMsg{r.resolved()} }
+}
+
+// refHint describes the highlighted pick for the hint line.
+func (m Model) refHint() string {
+	items := m.picks()
+	if len(items) == 0 {
+		return ""
+	}
+	it, ok := m.picked()
+	if !ok {
+		return fmt.Sprintf(" • n/N pick one of %d items", len(items))
+	}
+	hint := fmt.Sprintf(" • %s (%d/%d): y copy", it.ID, m.ref+1, len(items))
+	if it.URL != "" {
+		hint += ", u copy link, o open"
+	}
+	if _, ok := it.reference(m.jiraProjects()); ok {
+		hint += ", t show in tab"
+	}
+	if m.copied != "" {
+		hint += " • copied " + m.copied
+	}
+	return hint
+}
@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"reflect"
+	"testing"
+
+	"termiflow/gitrepo"
+)
+
+func TestReferences(t *testing.T) {
+	projects := map[string]bool{"PROJ": true, "OPS": true}
+	tests := []struct {
+		name string
+		text string
+		want []Reference
+	}{
+		{"jira keys in order", "See OPS-7, then PROJ-12.", []Reference{{Key: "OPS-7"}, {Key: "PROJ-12"}}},
+		{"unknown projects", "GPT-4 uses AES-256; COVID-19 and UTF-8 too", nil},
+		{"known and unknown", "PROJ-1 mentions GPT-4", []Reference{{Key: "PROJ-1"}}},
+		{"no zero issue", "PROJ-0", nil},
+		{"lowercase", "proj-12", nil},
+		{"bare github number", "fixed in #42", []Reference{{Number: 42}}},
+		{"github with repo", "see acme/app#7", []Reference{{Repo: "acme/app", Number: 7}}},
+		{"mixed, repeats dropped", "#3 and PROJ-1, again #3 and PROJ-1", []Reference{{Number: 3}, {Key: "PROJ-1"}}},
+		{"bare and qualified differ", "#3 vs acme/app#3", []Reference{{Number: 3}, {Repo: "acme/app", Number: 3}}},
+		{"no #0", "item #0", nil},
+		{"heading marker", "# Summary", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := references(tt.text, projects); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("references(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJiraProjects(t *testing.T) {
+	m := Model{messages: []Message{
+		{Role: "model", Content: "GPT-4 says PROJ-1"},
+		{Role: "tool", Items: []toolItem{{ID: "PROJ-1"}, {ID: "OPS-22"}, {ID: "#4"}, {ID: "build-7"}}},
+	}}
+	want := map[string]bool{"PROJ": true, "OPS": true}
+	if got := m.jiraProjects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("jiraProjects() = %v, want %v", got, want)
+	}
+}
+
+func TestNewToolItem(t *testing.T) {
+	t.Setenv("JIRA_URL", "https://acme.atlassian.net/")
+	repo := gitrepo.Default()
+	tests := []struct {
+		name string
+		obj  map[string]any
+		want toolItem
+		ok   bool
+	}{
+		{"jira issue",
+			map[string]any{"key": "PROJ-1", "summary": "Fix login"},
+			toolItem{ID: "PROJ-1", Title: "Fix login", URL: "https://acme.atlassian.net/browse/PROJ-1"}, true},
+		{"github issue with its own link",
+			map[string]any{"number": 7.0, "title": "Crash", "html_url": "https://github.com/acme/app/issues/7"},
+			toolItem{ID: "#7", Title: "Crash", URL: "https://github.com/acme/app/issues/7"}, true},
+		{"github issue without a link",
+			map[string]any{"number": 7.0, "title": "Crash"},
+			toolItem{ID: "#7", Title: "Crash", URL: "https://github.com/" + repo + "/issues/7"}, true},
+		{"number as a string",
+			map[string]any{"number": "12"},
+			toolItem{ID: "#12", URL: "https://github.com/" + repo + "/issues/12"}, true},
+		{"other id",
+			map[string]any{"id": 99.0, "name": "build", "web_url": "https://ci.example.com/99"},
+			toolItem{ID: "99", Title: "build", URL: "https://ci.example.com/99"}, true},
+		{"url only",
+			map[string]any{"url": "https://example.com/x"},
+			toolItem{URL: "https://example.com/x"}, true},
+		{"title first of several",
+			map[string]any{"id": "a", "summary": "S", "title": "T", "name": "N"},
+			toolItem{ID: "a", Title: "S"}, true},
+		{"nothing to pick", map[string]any{"title": "lonely", "count": 3.0}, toolItem{Title: "lonely"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newToolItem(tt.obj)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("newToolItem(%v) = %+v, %v; want %+v, %v", tt.obj, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package chat
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"termiflow/httpclient"
+	"termiflow/storage"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- REST Tools --
+
+// restToolsFile declares extra HTTP endpoints Gemini may call, e.g.
+//
+//	[{"name": "get_build", "description": "Look up a CI build",
+//	  "url": "https://ci.internal/api/builds/{id}",
+//	  "headers": {"Authorization": "Bearer ${CI_TOKEN}"},
+//	  "params": [{"name": "id", "in": "path", "required": true}]}]
+//
+// Header values expand environment variables so secrets stay out of the file.
+const restToolsFile = "tools.json"
+
+type restTool struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Method      string            `json:"method"` // default GET
+	URL         string            `json:"url"`    // {param} placeholders fill path params
+	Headers     map[string]string `json:"headers"`
+	Params      []restParam       `json:"params"`
+}
+
+type restParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	In          string `json:"in"` // "path" or "query" (default)
+	Required    bool   `json:"required"`
+}
+
+const (
+	// maxRESTBody caps how much of a response is handed to the model.
+	maxRESTBody = 4000
+	// maxRESTRead caps how much is read, so a JSON body somewhat over
+	// maxRESTBody can still be decoded and shortened as data.
+	maxRESTRead = 1 << 20
+)
+
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,63}$`)
+
+// loadRESTTools reads restToolsFile. Invalid entries are reported but don't
+// stop the valid ones from loading.
+func loadRESTTools() (map[string]restTool, error) {
+	var list []restTool
+	if err := storage.LoadJSON(restToolsFile, &list); err != nil {
+		return nil, err
+	}
+	tools := map[string]restTool{}
+	var bad []string
+	for _, t := range list {
+		_, builtin := toolFunctions[t.Name]
+		_, dup := tools[t.Name]
+		if !toolNamePattern.MatchString(t.Name) || builtin || dup || t.URL == "" {
+			bad = append(bad, fmt.Sprintf("%q", t.Name))
+			continue
+		}
+		tools[t.Name] = t
+	}
+	if len(bad) > 0 {
+		return tools, fmt.Errorf("skipped invalid tools %s (names must be unique identifiers with a url)", strings.Join(bad, ", "))
+	}
+	return tools, nil
+}
+
+// declaration describes the endpoint to Gemini; every param is a string.
+func (t restTool) declaration() *genai.FunctionDeclaration {
+	decl := &genai.FunctionDeclaration{Name: t.Name, Description: t.Description}
+	if len(t.Params) == 0 {
+		return decl
+	}
+	schema := &genai.Schema{Type: genai.TypeObject, Properties: map[string]*genai.Schema{}}
+	for _, p := range t.Params {
+		schema.Properties[p.Name] = &genai.Schema{Type: genai.TypeString, Description: p.Description}
+		if p.Required || p.In == "path" {
+			schema.Required = append(schema.Required, p.Name)
+		}
+	}
+	decl.Parameters = schema
+	return decl
+}
+
+// requestURL fills path placeholders and query params from args.
+func (t restTool) requestURL(args map[string]any) (string, error) {
+	u := t.URL
+	q := url.Values{}
+	for _, p := range t.Params {
+		v, ok := args[p.Name]
+		if !ok {
+			if p.Required || p.In == "path" {
+				return "", fmt.Errorf("missing required parameter %q", p.Name)
+			}
+			continue
+		}
+		s := fmt.Sprint(v)
+		if p.In == "path" {
+			u = strings.ReplaceAll(u, "{"+p.Name+"}", url.PathEscape(s))
+		} else {
+			q.Set(p.Name, s)
+		}
+	}
+	if len(q) == 0 {
+		return u, nil
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + q.Encode(), nil
+}
+
+// call performs the request and returns the decoded JSON response.
+func (t restTool) call(args map[string]any) (map[string]any, error) {
+	u, err := t.requestURL(args)
+	if err != nil {
+		return nil, err
+	}
+	method := strings.ToUpper(t.Method)
+	if method == "" {
+		method = "GET"
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", httpclient.UserAgent())
+	for k, v := range t.Headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	client := httpclient.New(10 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s API Error: %s", t.Name, resp.Status)
+	}
+	// One byte past the cap tells a body that fits from one that doesn't,
+	// without reading an endless response into memory
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRESTRead+1))
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{"status": resp.StatusCode}
+	body, cut := restBody(data)
+	result["body"] = body
+	if cut {
+		result["truncated"] = fmt.Sprintf("the response was shortened to about %d bytes: lists keep their first items and long text is cut", maxRESTBody)
+	}
+	return result, nil
+}
+
+// restBody turns a response body into a tool result: decoded JSON when it
+// parses, text otherwise, shortened to about maxRESTBody bytes. Function
+// responses must be valid UTF-8, so other bytes become U+FFFD.
+func restBody(data []byte) (any, bool) {
+	var body any
+	if len(data) <= maxRESTRead && json.Unmarshal(data, &body) == nil {
+		return fitJSON(body, maxRESTBody)
+	}
+	text := strings.ToValidUTF8(string(data), "\uFFFD")
+	if len(text) <= maxRESTBody {
+		return text, false
+	}
+	return clipText(text, maxRESTBody) + "…", true
+}
+
+// fitJSON shrinks a decoded JSON value until it encodes in about limit
+// bytes: lists keep their first items, objects share the room between
+// their fields (smallest first) and strings are clipped. It reports
+// whether anything was cut.
+func fitJSON(v any, limit int) (any, bool) {
+	if jsonSize(v) <= limit {
+		return v, false
+	}
+	switch v := v.(type) {
+	case string:
+		return clipText(v, max(limit-len(`"…"`), 0)) + "…", true
+	case []any:
+		out := []any{}
+		left := limit - len("[]")
+		for _, item := range v {
+			item, _ = fitJSON(item, left-len(","))
+			n := jsonSize(item) + len(",")
+			if n > left {
+				break
+			}
+			out = append(out, item)
+			left -= n
+		}
+		return out, true
+	case map[string]any:
+		keys := slices.SortedFunc(maps.Keys(v), func(a, b string) int {
+			return cmp.Compare(jsonSize(v[a])+len(a), jsonSize(v[b])+len(b))
+		})
+		out := map[string]any{}
+		left := limit - len("{}")
+		for i, k := range keys {
+			field := jsonSize(k) + len(":,")
+			item, _ := fitJSON(v[k], left/(len(keys)-i)-field)
+			if n := jsonSize(item) + field; n <= left {
+				out[k] = item
+				left -= n
+			}
+		}
+		return out, true
+	}
+	// Numbers, booleans and null can't shrink
+	return v, false
+}
+
+// jsonSize is how many bytes v takes encoded.
+func jsonSize(v any) int {
+	data, _ := json.Marshal(v)
+	return len(data)
+}
+
+// clipText cuts s to at most n bytes without splitting a character.
+func clipText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// chatTools returns the built-in tools plus the configured REST endpoints.
+func chatTools(rest map[string]restTool) []*genai.Tool {
+	if len(rest) == 0 {
+		return tools
+	}
+	decls := append([]*genai.FunctionDeclaration(nil), tools[0].FunctionDeclarations...)
+	names := make([]string, 0, len(rest))
+	for name := range rest {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		decls = append(decls, rest[name].declaration())
+	}
+	return []*genai.Tool{{FunctionDeclarations: decls}}
+}
+
+// toolFunc looks up a built-in or REST tool by name.
+func (m Model) toolFunc(name string) (func(args map[string]any) (map[string]any, error), bool) {
+	if fn, ok := toolFunctions[name]; ok {
+		// Built-in tools take no arguments; they use env vars
+		return func(map[string]any) (map[string]any, error) { return fn() }, true
+	}
+	t, ok := m.restTools[name]
+	return t.call, ok
+}
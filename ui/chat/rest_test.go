@@ -0,0 +1,110 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRESTBody(t *testing.T) {
+	var items []string
+	for i := range 500 {
+		items = append(items, fmt.Sprintf(`{"id":%d,"name":"build %d"}`, i, i))
+	}
+	bigList := `{"total":500,"items":[` + strings.Join(items, ",") + `]}`
+
+	tests := []struct {
+		name    string
+		data    string
+		want    any // nil: only check the size and encoding
+		cut     bool
+		isJSON  bool
+		hasText string
+	}{
+		{name: "small json", data: `{"ok":true,"n":3}`, want: map[string]any{"ok": true, "n": 3.0}, isJSON: true},
+		{name: "small text", data: "plain answer", want: "plain answer"},
+		{name: "big json list", data: bigList, cut: true, isJSON: true},
+		{name: "long json string", data: `{"log":"` + strings.Repeat("é", 5000) + `","id":7}`, cut: true, isJSON: true},
+		{name: "long multibyte text", data: strings.Repeat("日本語", 2000), cut: true, hasText: "日本語"},
+		{name: "binary", data: "\x89PNG\r\n\x1a\n\xff\xfe\x00", want: "�PNG\r\n\x1a\n�\x00"},
+		{name: "json over the read cap", data: `["` + strings.Repeat("x", maxRESTRead) + `"]`, cut: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cut := restBody([]byte(tt.data))
+			if cut != tt.cut {
+				t.Errorf("cut = %v, want %v", cut, tt.cut)
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("body = %#v, want %#v", got, tt.want)
+			}
+			if _, isText := got.(string); tt.isJSON == isText {
+				t.Errorf("body is %T, want decoded JSON: %v", got, tt.isJSON)
+			}
+			if s, ok := got.(string); ok {
+				if len(s) > maxRESTBody+len("…") {
+					t.Errorf("body is %d bytes, want at most %d", len(s), maxRESTBody)
+				}
+				if !utf8.ValidString(s) {
+					t.Errorf("body is not valid UTF-8: %q", s)
+				}
+			} else if n := jsonSize(got); n > maxRESTBody {
+				t.Errorf("body encodes to %d bytes, want at most %d", n, maxRESTBody)
+			}
+			if s, _ := got.(string); tt.hasText != "" && !strings.HasPrefix(s, tt.hasText) {
+				t.Errorf("body = %.20q…, want it to start with %q", s, tt.hasText)
+			}
+		})
+	}
+}
+
+func TestFitJSONKeepsStructure(t *testing.T) {
+	var body any
+	data := `{"total":500,"items":[` + strings.Repeat(`{"id":1,"name":"build"},`, 499) + `{"id":1,"name":"build"}]}`
+	if err := json.Unmarshal([]byte(data), &body); err != nil {
+		t.Fatal(err)
+	}
+	got, cut := fitJSON(body, 400)
+	if !cut {
+		t.Fatal("not cut")
+	}
+	obj, ok := got.(map[string]any)
+	if !ok {
+		t.Fatalf("got %T, want an object", got)
+	}
+	if obj["total"] != 500.0 {
+		t.Errorf("total = %v, want the small field kept", obj["total"])
+	}
+	list, _ := obj["items"].([]any)
+	if len(list) == 0 || len(list) >= 500 {
+		t.Errorf("kept %d items, want the first few", len(list))
+	}
+	if !reflect.DeepEqual(list[0], map[string]any{"id": 1.0, "name": "build"}) {
+		t.Errorf("first item = %v, want it whole", list[0])
+	}
+	if n := jsonSize(got); n > 400 {
+		t.Errorf("encodes to %d bytes, want at most 400", n)
+	}
+}
+
+func TestClipText(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is two bytes; don't split it
+		{"日本", 4, "日"},
+		{"日本", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := clipText(tt.s, tt.n); got != tt.want {
+			t.Errorf("clipText(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"termiflow/storage"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Saved Conversations --
+
+// sessionsDir holds one JSON file per conversation put aside with /clear
+// or by switching to another one.
+const sessionsDir = "sessions"
+
+// maxSessions caps the saved conversations; the oldest go first.
+const maxSessions = 50
+
+type savedChat struct {
+	Saved    time.Time `json:"saved"`
+	Messages []Message `json:"messages"`
+	path     string
+}
+
+// title names a conversation by its first question.
+func (c savedChat) title() string {
+	for _, msg := range c.Messages {
+		if msg.Role == "user" {
+			line, _, _ := strings.Cut(strings.TrimSpace(msg.Content), "\n")
+			if r := []rune(line); len(r) > 60 {
+				line = string(r[:60]) + "…"
+			}
+			return line
+		}
+	}
+	return "(no questions)"
+}
+
+func sessionsPath() (string, error) {
+	dir, err := storage.Path(sessionsDir)
+	if err != nil {
+		return "", err
+	}
+	return dir, os.MkdirAll(dir, 0o700)
+}
+
+// listSessions returns the saved conversations, newest first. Files that
+// can't be read are skipped.
+func listSessions() ([]savedChat, error) {
+	dir, err := sessionsPath()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var chats []savedChat
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var c savedChat
+		if json.Unmarshal(data, &c) != nil {
+			continue
+		}
+		c.path = path
+		chats = append(chats, c)
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].Saved.After(chats[j].Saved) })
+	return chats, nil
+}
+
+// saveSession stores msgs as a new saved conversation, unless it has no
+// questions worth keeping, and drops the oldest beyond maxSessions.
+func saveSession(msgs []Message) error {
+	if !slices.ContainsFunc(msgs, func(msg Message) bool { return msg.Role == "user" }) {
+		return nil
+	}
+	dir, err := sessionsPath()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	data, err := json.MarshalIndent(savedChat{Saved: now, Messages: msgs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d.json", now.UnixNano()))
+	if err := storage.WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+	chats, err := listSessions()
+	if err != nil {
+		return err
+	}
+	for _, c := range chats[min(len(chats), maxSessions):] {
+		os.Remove(c.path)
+	}
+	return nil
+}
+
+// historyFrom rebuilds Gemini's view of a conversation from its messages.
+// System notes were never sent and tool results are left out, since they
+// can't be replayed without the calls they answered; the answers built on
+// them keep what mattered. Consecutive turns by the same role
+// (e.g. text before and after a tool approval) are merged so roles
+// alternate. The API wants a history that starts with the user and ends
+// with the model, so anything before the first question is dropped, and so
+// is a last question that never got an answer.
+func historyFrom(msgs []Message) []*genai.Content {
+	var history []*genai.Content
+	for _, msg := range msgs {
+		role, text := msg.Role, msg.Content
+		if role != "user" && role != "model" {
+			continue
+		}
+		if strings.TrimSpace(text) == "" || (len(history) == 0 && role != "user") {
+			continue
+		}
+		if n := len(history); n > 0 && history[n-1].Role == role {
+			history[n-1].Parts = append(history[n-1].Parts, genai.Text(text))
+			continue
+		}
+		history = append(history, &genai.Content{Role: role, Parts: []genai.Part{genai.Text(text)}})
+	}
+	if n := len(history); n > 0 && history[n-1].Role == "user" {
+		history = history[:n-1]
+	}
+	return history
+}
+
+// rebuildSession has Gemini continue from msgs on the next turn, so a
+// conversation shown on screen is one it remembers too.
+func (m *Model) rebuildSession(msgs []Message) {
+	m.sess.startOver(historyFrom(msgs))
+}
+
+// clearConversation saves the conversation and starts a new one, both on
+// screen and with Gemini.
+func (m *Model) clearConversation() string {
+	if len(m.messages) == 0 {
+		return "Nothing to clear."
+	}
+	if err := saveSession(m.messages); err != nil {
+		return fmt.Sprintf("Not cleared: %v", err)
+	}
+	m.startOver(nil)
+	return "Started a new conversation. Type /sessions to switch back to earlier ones."
+}
+
+// loadSession swaps the current conversation for a saved one, which leaves
+// the saved list; the current one takes its place there.
+func (m *Model) loadSession(c savedChat) string {
+	if err := saveSession(m.messages); err != nil {
+		return fmt.Sprintf("Not loaded: %v", err)
+	}
+	if err := os.Remove(c.path); err != nil {
+		return fmt.Sprintf("Not loaded: %v", err)
+	}
+	m.startOver(c.Messages)
+	return fmt.Sprintf("Loaded the conversation from %s.", c.Saved.Format("2006-01-02 15:04"))
+}
+
+// startOver replaces the conversation with msgs and has Gemini continue
+// from them. Pending tool approvals belong to the old conversation.
+func (m *Model) startOver(msgs []Message) {
+	m.messages = append([]Message{}, msgs...)
+	m.pending = nil
+	m.rebuildSession(msgs)
+	_ = storage.SaveJSON(historyFile, m.messages) // best effort; Shutdown saves again
+}
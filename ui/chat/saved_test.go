@@ -0,0 +1,129 @@
+package chat
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+func TestRebuildSession(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []Message
+		want []*genai.Content
+	}{
+		{"empty", nil, nil},
+		{"only notes", []Message{{Role: "system", Content: "Could not load chat history"}}, nil},
+		{
+			"notes and tools left out",
+			[]Message{
+				{Role: "system", Content: "Welcome back"},
+				{Role: "model", Content: "answer without a question"},
+				{Role: "user", Content: "my issues?"},
+				{Role: "tool", Summary: "[get_jira_issues → 2 issues]", Content: `{"issues": []}`},
+				{Role: "model", Content: "You have two."},
+				{Role: "system", Content: "[Retry cancelled]"},
+				{Role: "user", Content: "which is older?"},
+				{Role: "model", Content: "Checking."},
+				{Role: "tool", Summary: "[get_jira_issues → 2 issues]", Content: `{"issues": []}`},
+				{Role: "model", Content: "PROJ-1."},
+				{Role: "user", Content: "unanswered"},
+			},
+			[]*genai.Content{
+				{Role: "user", Parts: []genai.Part{genai.Text("my issues?")}},
+				{Role: "model", Parts: []genai.Part{genai.Text("You have two.")}},
+				{Role: "user", Parts: []genai.Part{genai.Text("which is older?")}},
+				{Role: "model", Parts: []genai.Part{genai.Text("Checking."), genai.Text("PROJ-1.")}},
+			},
+		},
+		{
+			"same role merged",
+			[]Message{
+				{Role: "user", Content: "first"},
+				{Role: "system", Content: "[Cancelled]"},
+				{Role: "user", Content: "second"},
+				{Role: "model", Content: "  "},
+				{Role: "model", Content: "both"},
+			},
+			[]*genai.Content{
+				{Role: "user", Parts: []genai.Part{genai.Text("first"), genai.Text("second")}},
+				{Role: "model", Parts: []genai.Part{genai.Text("both")}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{sess: &session{}}
+			m.rebuildSession(tt.msgs)
+			got := *m.sess.next.Load()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("history = %s, want %s", dump(got), dump(tt.want))
+			}
+			for i, c := range got {
+				want := "user"
+				if i%2 == 1 {
+					want = "model"
+				}
+				if c.Role != want {
+					t.Errorf("turn %d is %s, want %s: roles must alternate from the user", i, c.Role, want)
+				}
+			}
+		})
+	}
+}
+
+// The rebuilt history must be what the next send carries: prepare swaps it
+// into the chat session, replacing whatever that held.
+func TestRebuildSessionPrepare(t *testing.T) {
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	model := client.GenerativeModel("gemini-test")
+	sess := &session{model: model, chat: model.StartChat()}
+	sess.chat.History = []*genai.Content{{Role: "user", Parts: []genai.Part{genai.Text("stale")}}}
+
+	m := Model{sess: sess}
+	m.rebuildSession([]Message{
+		{Role: "system", Content: "Welcome back"},
+		{Role: "user", Content: "my issues?"},
+		{Role: "tool", Summary: "[get_jira_issues → 2 issues]", Content: `{"issues": []}`},
+		{Role: "model", Content: "You have two."},
+	})
+	if err := sess.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	want := []*genai.Content{
+		{Role: "user", Parts: []genai.Part{genai.Text("my issues?")}},
+		{Role: "model", Parts: []genai.Part{genai.Text("You have two.")}},
+	}
+	if got := sess.chat.History; !reflect.DeepEqual(got, want) {
+		t.Fatalf("chat history = %s, want %s", dump(got), dump(want))
+	}
+	if sess.next.Load() != nil {
+		t.Error("pending history left after prepare; the next send would apply it again")
+	}
+
+	// A second prepare has nothing new to apply
+	sess.chat.History = append(sess.chat.History, &genai.Content{Role: "user", Parts: []genai.Part{genai.Text("more")}})
+	if err := sess.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(sess.chat.History); n != 3 {
+		t.Errorf("history has %d turns after a second prepare, want the 3 it had", n)
+	}
+}
+
+func dump(history []*genai.Content) []string {
+	var s []string
+	for _, c := range history {
+		for _, p := range c.Parts {
+			s = append(s, c.Role+": "+string(p.(genai.Text)))
+		}
+	}
+	return s
+}
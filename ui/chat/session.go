@@ -0,0 +1,199 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"termiflow/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Gemini Session --
+
+// session owns the Gemini client and the conversation.
+//
+// Threading model: Model is a value type that Bubble Tea copies on every
+// Update, and commands run on their own goroutines with yet another copy.
+// New allocates one *session and every copy shares that pointer, so the
+// client is created once (by ensureClient, on first use) and the history
+// survives across copies. mu guards all fields and serializes turns: a
+// ChatSession appends to History around each SendMessage and overlapping
+// sends would interleave or race on it. Command closures hold mu for a
+// whole turn, including follow-up tool rounds; Update never blocks on it
+// once the client is open, thanks to the ready fast path.
+type session struct {
+	ready  atomic.Bool                      // client is open; checked without taking mu
+	stale  atomic.Bool                      // Gemini settings changed; reconnect before the next turn
+	next   atomic.Pointer[[]*genai.Content] // history to continue from, after /clear or loading a session
+	sent   atomic.Int64                     // requests made this run, shown in the footer
+	picked atomic.Pointer[string]           // preset chosen with alt+m, overriding GEMINI_MODEL this run
+	mu     sync.Mutex
+	client *genai.Client
+	model  *genai.GenerativeModel
+	name   string // GEMINI_MODEL, without the Vertex prefix
+	chat   *genai.ChatSession
+	rest   map[string]restTool
+	spent  time.Duration // waiting on Gemini this turn; see send
+}
+
+// open creates the client and chat session unless that already happened.
+func (s *session) open(rest map[string]restTool) error {
+	if s.ready.Load() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		return nil
+	}
+	s.rest = rest
+	return s.connect()
+}
+
+// connect creates the client and a fresh chat session from the current
+// settings. The caller holds mu.
+func (s *session) connect() error {
+	c, err := gemini.NewClient(context.Background())
+	if err != nil {
+		return err
+	}
+	s.client = c
+	s.name = s.modelName()
+	s.model = c.GenerativeModel(gemini.FullModelName(s.name))
+	s.model.Tools = chatTools(s.rest)
+	s.chat = s.model.StartChat()
+	s.ready.Store(true)
+	return nil
+}
+
+// send sends parts in the current conversation, counting the request. An
+// empty response is retried once after gemini.EmptyRetryDelay; if that is
+// empty too it becomes an error saying why, when Gemini gave a reason. The
+// caller holds mu.
+func (s *session) send(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
+	start := time.Now()
+	defer func() { s.spent += time.Since(start) }()
+	s.sent.Add(1)
+	resp, err := s.chat.SendMessage(ctx, parts...)
+	if err != nil || !gemini.Empty(resp) {
+		return resp, err
+	}
+	dropEmptyTurn(s.chat)
+	select {
+	case <-time.After(gemini.EmptyRetryDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	s.sent.Add(1)
+	resp, err = s.chat.SendMessage(ctx, parts...)
+	if err == nil && gemini.Empty(resp) {
+		dropEmptyTurn(s.chat)
+		return nil, gemini.EmptyResponseError(resp)
+	}
+	return resp, err
+}
+
+// timed records the Gemini time spent this turn on a response for Update.
+// The caller holds mu.
+func (s *session) timed(msg tea.Msg) tea.Msg {
+	if r, ok := msg.(responseMsg); ok {
+		r.took = s.spent
+		return r
+	}
+	return msg
+}
+
+// dropEmptyTurn removes an exchange that got an empty response from the
+// history: the contentless model entry, if one was added, and what was
+// sent, so a retry doesn't send it twice.
+func dropEmptyTurn(cs *genai.ChatSession) {
+	if n := len(cs.History); n > 0 && cs.History[n-1].Role == "model" && len(cs.History[n-1].Parts) == 0 {
+		cs.History = cs.History[:n-1]
+	}
+	dropFailedTurn(cs)
+}
+
+// modelName is the model turns use: the picked preset, or GEMINI_MODEL.
+// Unlike name it is current before the reconnect, so the footer can show it.
+func (s *session) modelName() string {
+	if p := s.picked.Load(); p != nil {
+		return *p
+	}
+	return gemini.ModelName()
+}
+
+// pick switches to model from the next turn, or back to GEMINI_MODEL for
+// "". Like invalidate it doesn't wait for a turn in flight.
+func (s *session) pick(model string) {
+	if model == "" {
+		s.picked.Store(nil)
+	} else {
+		s.picked.Store(&model)
+	}
+	s.invalidate()
+}
+
+// invalidate makes the next turn reconnect with the current settings, e.g.
+// after GEMINI_MODEL changed. It doesn't wait for a turn in flight.
+func (s *session) invalidate() {
+	s.stale.Store(true)
+}
+
+// startOver makes the next turn continue from history instead of the
+// current conversation: nil after /clear, or a saved conversation that was
+// loaded back. Like invalidate it doesn't wait for a turn in flight.
+func (s *session) startOver(history []*genai.Content) {
+	s.next.Store(&history)
+}
+
+// prepare applies pending invalidate and startOver requests before a turn.
+// The caller holds mu.
+func (s *session) prepare() error {
+	if err := s.reconnectIfStale(); err != nil {
+		return err
+	}
+	if s.chat == nil {
+		return nil
+	}
+	if h := s.next.Swap(nil); h != nil {
+		s.chat = s.model.StartChat()
+		s.chat.History = *h
+	}
+	return nil
+}
+
+// reconnectIfStale swaps in a new client after invalidate, carrying the
+// conversation over. If the new settings don't work the old client stays.
+// The caller holds mu.
+func (s *session) reconnectIfStale() error {
+	if s.client == nil || !s.stale.Swap(false) {
+		return nil
+	}
+	oldClient, oldChat := s.client, s.chat
+	if err := s.connect(); err != nil {
+		return err // connect only replaces the fields on success
+	}
+	s.chat.History = oldChat.History
+	_ = oldClient.Close()
+	return nil
+}
+
+// close releases the client's connections. A turn still in flight keeps
+// the client, since quitting shouldn't wait on the network.
+func (s *session) close() error {
+	if !s.mu.TryLock() {
+		return nil
+	}
+	defer s.mu.Unlock()
+	if s.client == nil {
+		return nil
+	}
+	s.ready.Store(false)
+	err := s.client.Close()
+	s.client, s.model, s.chat = nil, nil, nil
+	return err
+}
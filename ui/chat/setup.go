@@ -0,0 +1,117 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/gemini"
+	"termiflow/ui/focus"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- API Key Setup --
+
+var bannerStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#FFAF00")).
+	Padding(0, 1)
+
+// keyURL is where Gemini API keys are created.
+const keyURL = "https://aistudio.google.com/app/apikey"
+
+// needsSetup reports whether chatting is impossible for lack of an API key.
+// Vertex AI uses Application Default Credentials instead, and demo mode
+// needs nothing.
+func needsSetup() bool {
+	return !demo.Enabled() && !gemini.Configured()
+}
+
+func setupBanner(width int) string {
+	text := strings.Join([]string{
+		lipgloss.NewStyle().Bold(true).Render("Gemini needs an API key"),
+		"Get one at " + keyURL,
+		"Type /setup to enter it for this session, or export GEMINI_API_KEY and restart.",
+		"For Vertex AI set GEMINI_BACKEND=vertex and GOOGLE_CLOUD_PROJECT instead.",
+		systemStyle.Render("esc dismiss"),
+	}, "\n")
+	return bannerStyle.Width(max(width-2, 20)).Render(text)
+}
+
+func newKeyInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "API key: "
+	ti.Placeholder = "paste and press Enter"
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	return ti
+}
+
+// startKeyEntry swaps the chat input for a masked key prompt.
+func (m *Model) startKeyEntry() tea.Cmd {
+	m.keyEntry = true
+	m.keyInput.Reset()
+	return focus.Set(&m.keyInput, append(m.parts(), &m.keyInput)...)
+}
+
+// updateKeyEntry handles keys while the key prompt is open. The key only
+// lives in this process's environment, like one exported before starting.
+func (m Model) updateKeyEntry(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.keyEntry = false
+		m.keyInput.Blur()
+		return m, m.focusInput()
+	case tea.KeyEnter:
+		key := strings.TrimSpace(m.keyInput.Value())
+		if key == "" {
+			return m, nil
+		}
+		os.Setenv("GEMINI_API_KEY", key)
+		m.sess.invalidate()
+		m.keyEntry = false
+		m.banner = false
+		m.keyInput.Reset()
+		m.keyInput.Blur()
+		m.textarea.Placeholder = askPlaceholder
+		if config.KeyringEnabled() {
+			// Offer the keyring rather than asking users to export a secret
+			m.keyToSave = key
+			m.messages = append(m.messages, Message{Role: "system", Content: "API key set for this session. Save it to the OS keyring so it's used next time? (y/n)"})
+		} else {
+			m.messages = append(m.messages, Message{Role: "system", Content: "API key set for this session. Export GEMINI_API_KEY to keep it next time."})
+		}
+		m.updateViewport()
+		return m, m.focusInput()
+	}
+	var cmd tea.Cmd
+	m.keyInput, cmd = m.keyInput.Update(msg)
+	return m, cmd
+}
+
+// answerKeyring handles the answer to the offer to save a /setup key in the
+// OS keyring.
+func (m Model) answerKeyring(key string) (Model, tea.Cmd) {
+	var note string
+	switch key {
+	case "y":
+		if err := config.SaveSecret("GEMINI_API_KEY", m.keyToSave); err != nil {
+			note = fmt.Sprintf("Could not save to the keyring: %v. Export GEMINI_API_KEY to keep the key next time.", err)
+		} else {
+			note = "API key saved to the OS keyring."
+		}
+	case "n", "esc":
+		note = "Not saved. Export GEMINI_API_KEY to keep the key next time."
+	default:
+		return m, nil
+	}
+	m.keyToSave = ""
+	m.messages = append(m.messages, Message{Role: "system", Content: note})
+	m.updateViewport()
+	return m, nil
+}
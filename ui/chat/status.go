@@ -0,0 +1,149 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/gemini"
+	"termiflow/httpclient"
+	"termiflow/jiraapi"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// -- Connection Status --
+
+type statusMsg struct {
+	lines []string
+}
+
+// checkStatus verifies each integration's credentials in parallel.
+// clientErr is the error, if any, from setting up the Gemini client.
+func (m Model) checkStatus(clientErr error) tea.Cmd {
+	return func() tea.Msg {
+		if demo.Enabled() {
+			return statusMsg{lines: []string{"Demo mode: no integrations are contacted"}}
+		}
+		checks := []func() string{checkJira, checkGitHub, func() string { return m.checkGemini(clientErr) }}
+		lines := make([]string, len(checks))
+		var wg sync.WaitGroup
+		for i, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lines[i] = check()
+			}()
+		}
+		wg.Wait()
+		return statusMsg{lines: append(lines, customHeaders()...)}
+	}
+}
+
+// customHeaders describes the extra headers sent to each integration, with
+// credentials masked.
+func customHeaders() []string {
+	var lines []string
+	for _, name := range []string{httpclient.GitHub, httpclient.Jira} {
+		headers, err := httpclient.Headers(name)
+		switch {
+		case err != nil:
+			return []string{fmt.Sprintf("Headers: %v", err)}
+		case len(headers) > 0:
+			lines = append(lines, fmt.Sprintf("Headers (%s): %s", name, httpclient.Describe(headers)))
+		}
+	}
+	return lines
+}
+
+func checkJira() string {
+	if !jiraapi.Configured() {
+		return "Jira: not configured"
+	}
+	req, _ := http.NewRequest("GET", os.Getenv("JIRA_URL")+"/rest/api/3/myself", nil)
+	jiraapi.SetAuth(req)
+	req.Header.Add("Accept", "application/json")
+	httpclient.SetHeaders(req, httpclient.Jira)
+	client, err := httpclient.NewWithTLS(10*time.Second, httpclient.TLSFromEnv("JIRA"))
+	if err != nil {
+		return fmt.Sprintf("Jira: %v", err)
+	}
+	var me struct {
+		DisplayName  string `json:"displayName"`
+		EmailAddress string `json:"emailAddress"`
+	}
+	if err := getJSON(client, req, httpclient.Jira, &me); err != nil {
+		return err.Error()
+	}
+	who := me.EmailAddress
+	if who == "" {
+		who = me.DisplayName
+	}
+	return "Jira: connected as " + who
+}
+
+func checkGitHub() string {
+	token := config.Secret("GITHUB_TOKEN")
+	if token == "" {
+		return "GitHub: no GITHUB_TOKEN, using anonymous access (60 requests/hour)"
+	}
+	req, _ := http.NewRequest("GET", "https://api.github.com/user", nil)
+	req.Header.Add("Authorization", "Bearer "+token)
+	httpclient.SetHeaders(req, httpclient.GitHub)
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := getJSON(httpclient.New(10*time.Second), req, httpclient.GitHub, &user); err != nil {
+		return err.Error()
+	}
+	return "GitHub: authenticated as " + user.Login
+}
+
+// checkGemini counts the tokens of a one-word prompt: it needs valid
+// credentials but costs no generation quota.
+func (m Model) checkGemini(clientErr error) string {
+	if clientErr != nil {
+		return fmt.Sprintf("Gemini: %v", clientErr)
+	}
+	m.sess.mu.Lock()
+	model, name := m.sess.model, m.sess.name
+	m.sess.mu.Unlock()
+	if model == nil {
+		return "Gemini: not connected"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := model.CountTokens(ctx, genai.Text("ping")); err != nil {
+		return fmt.Sprintf("Gemini: %v", gemini.FriendlyError(err))
+	}
+	return fmt.Sprintf("Gemini: OK (%s)", name)
+}
+
+// getJSON fetches req into v; errors start with the integration's name.
+func getJSON(client *http.Client, req *http.Request, integration string, v any) error {
+	name := httpclient.DisplayName(integration)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == 200 {
+		return httpclient.DecodeJSON(resp, integration, v)
+	}
+	if integration == httpclient.Jira {
+		return fmt.Errorf("%s: %w", name, jiraapi.StatusError(resp))
+	}
+	return fmt.Errorf("%s: API Error: %s", name, resp.Status)
+}
+
+// formatStatus joins the check results into one message.
+func formatStatus(lines []string) string {
+	return "Status:\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Conversation Switcher --
+
+type sessionItem struct{ chat savedChat }
+
+func (i sessionItem) Title() string { return i.chat.title() }
+func (i sessionItem) Description() string {
+	return fmt.Sprintf("%s • %d messages", i.chat.Saved.Format("2006-01-02 15:04"), len(i.chat.Messages))
+}
+func (i sessionItem) FilterValue() string { return i.chat.title() }
+
+// openSwitcher lists the saved conversations in place of the current one.
+func (m *Model) openSwitcher() string {
+	chats, err := listSessions()
+	if err != nil {
+		return fmt.Sprintf("Could not list saved conversations: %v", err)
+	}
+	if len(chats) == 0 {
+		return "No saved conversations yet; /clear puts the current one aside."
+	}
+	items := make([]list.Item, len(chats))
+	for i, c := range chats {
+		items[i] = sessionItem{c}
+	}
+	l := list.New(items, list.NewDefaultDelegate(), m.viewport.Width, m.viewport.Height)
+	l.Title = "Saved conversations • enter load • d delete • esc back"
+	l.SetShowHelp(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	m.switcher = &l
+	return ""
+}
+
+// updateSwitcher handles keys while the switcher is open.
+func (m Model) updateSwitcher(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.switcher.FilterState() != list.Filtering {
+		sel, ok := m.switcher.SelectedItem().(sessionItem)
+		switch msg.String() {
+		case "esc":
+			if m.switcher.FilterState() == list.FilterApplied {
+				break // clears the filter
+			}
+			m.switcher = nil
+			return m, nil
+		case "enter":
+			if !ok {
+				return m, nil
+			}
+			m.switcher = nil
+			m.messages = append(m.messages, Message{Role: "system", Content: m.loadSession(sel.chat)})
+			m.updateViewport()
+			return m, nil
+		case "d":
+			if !ok {
+				return m, nil
+			}
+			if err := os.Remove(sel.chat.path); err != nil {
+				return m, m.switcher.NewStatusMessage(fmt.Sprintf("Could not delete: %v", err))
+			}
+			m.switcher.RemoveItem(m.switcher.GlobalIndex())
+			if len(m.switcher.Items()) == 0 {
+				m.switcher = nil
+				return m, nil
+			}
+			return m, m.switcher.NewStatusMessage("Deleted " + sel.chat.title())
+		}
+	}
+	var cmd tea.Cmd
+	*m.switcher, cmd = m.switcher.Update(msg)
+	return m, cmd
+}
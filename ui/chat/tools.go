@@ -1,34 +1,75 @@
 package chat
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"termiflow/config"
+	"termiflow/gitrepo"
+	"termiflow/httpclient"
+	"termiflow/jiraapi"
+
 	"github.com/google/generative-ai-go/genai"
 )
 
-// -- GitHub Tool --
+// -- Issue Results --
 
-func getGitHubIssues() (map[string]any, error) {
-	// Defaults to charmbracelet/bubbletea for demo, or env var
-	repo := os.Getenv("GITHUB_REPO")
-	if repo == "" {
-		repo = "charmbracelet/bubbletea" // Fallback
+// toolIssue is how both issue tools describe an issue, so Gemini sees the
+// same fields whichever tracker it came from.
+type toolIssue struct {
+	ID       string `json:"id"` // PROJ-123 or owner/repo#45
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	URL      string `json:"url"`
+	Assignee string `json:"assignee"` // "" when unassigned
+}
+
+// issuesResult is the result of get_github_issues and get_jira_issues.
+type issuesResult struct {
+	Source string      `json:"source"` // "github" or "jira"
+	Issues []toolIssue `json:"issues"`
+}
+
+// toolResponse converts a typed tool result to the generic map a function
+// response carries. Going through JSON keeps the struct tags as the field
+// names and leaves only the plain values the API accepts.
+func toolResponse(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
 	}
-	url := fmt.Sprintf("https://api.github.com/repos/%s/issues?state=open&per_page=5", repo)
+	var res map[string]any
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// -- GitHub Tool --
+
+// githubIssue holds the fields of a GitHub issue the tool reports.
+type githubIssue struct {
+	Number   int    `json:"number"`
+	Title    string `json:"title"`
+	State    string `json:"state"`
+	HTMLURL  string `json:"html_url"`
+	Assignee *struct {
+		Login string `json:"login"`
+	} `json:"assignee"`
+}
 
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Add("User-Agent", "TermiFlow")
-	token := os.Getenv("GITHUB_TOKEN")
+func getGitHubIssues() (map[string]any, error) {
+	repo := gitrepo.Default()
+	req, _ := http.NewRequest("GET", githubIssuesURL(repo), nil)
+	token := config.Secret("GITHUB_TOKEN")
 	if token != "" {
 		req.Header.Add("Authorization", "Bearer "+token)
 	}
+	httpclient.SetHeaders(req, httpclient.GitHub)
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := httpclient.New(5 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -39,43 +80,69 @@ func getGitHubIssues() (map[string]any, error) {
 		return nil, fmt.Errorf("GitHub API Error: %s", resp.Status)
 	}
 
-	var issues []map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+	var issues []githubIssue
+	if err := httpclient.DecodeJSON(resp, httpclient.GitHub, &issues); err != nil {
 		return nil, err
 	}
 
-	// Simplify output
-	var simplified []map[string]any
+	return toolResponse(githubResult(repo, issues))
+}
+
+func githubIssuesURL(repo string) string {
+	return fmt.Sprintf("https://api.github.com/repos/%s/issues?state=open&per_page=5", repo)
+}
+
+// githubResult maps the issues of repo to the shared schema.
+func githubResult(repo string, issues []githubIssue) issuesResult {
+	result := issuesResult{Source: "github", Issues: []toolIssue{}}
 	for _, i := range issues {
-		simplified = append(simplified, map[string]any{
-			"number": i["number"],
-			"title":  i["title"],
-			"user":   i["user"].(map[string]any)["login"],
-			"state":  i["state"],
-		})
+		issue := toolIssue{
+			ID:     fmt.Sprintf("%s#%d", repo, i.Number),
+			Title:  i.Title,
+			Status: i.State,
+			URL:    i.HTMLURL,
+		}
+		if i.Assignee != nil {
+			issue.Assignee = i.Assignee.Login
+		}
+		result.Issues = append(result.Issues, issue)
 	}
-
-	return map[string]any{"issues": simplified}, nil
+	return result
 }
 
 // -- Jira Tool --
 
-func getJiraIssues() (map[string]any, error) {
-	baseURL := os.Getenv("JIRA_URL")
-	email := os.Getenv("JIRA_EMAIL")
-	token := os.Getenv("JIRA_TOKEN")
+// jiraResult holds the fields of a Jira search the tool reports.
+type jiraResult struct {
+	Issues []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			Assignee *struct {
+				DisplayName string `json:"displayName"`
+			} `json:"assignee"`
+		} `json:"fields"`
+	} `json:"issues"`
+}
 
-	if baseURL == "" || email == "" || token == "" {
-		return nil, fmt.Errorf("Jira credentials not set (JIRA_URL, JIRA_EMAIL, JIRA_TOKEN)")
+func getJiraIssues() (map[string]any, error) {
+	if !jiraapi.Configured() {
+		return nil, fmt.Errorf("Jira credentials not set (JIRA_URL, JIRA_TOKEN, and JIRA_EMAIL unless JIRA_AUTH=bearer)")
 	}
 
-	url := fmt.Sprintf("%s/rest/api/3/search?jql=assignee=currentUser()&maxResults=5", baseURL)
-	req, _ := http.NewRequest("GET", url, nil)
-	auth := base64.StdEncoding.EncodeToString([]byte(email + ":" + token))
-	req.Header.Add("Authorization", "Basic "+auth)
+	baseURL := os.Getenv("JIRA_URL")
+	req, _ := http.NewRequest("GET", jiraSearchURL(baseURL), nil)
+	jiraapi.SetAuth(req)
 	req.Header.Add("Accept", "application/json")
+	httpclient.SetHeaders(req, httpclient.Jira)
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client, err := httpclient.NewWithTLS(5*time.Second, httpclient.TLSFromEnv("JIRA"))
+	if err != nil {
+		return nil, err
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -83,28 +150,38 @@ func getJiraIssues() (map[string]any, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Jira API Error: %s", resp.Status)
+		return nil, fmt.Errorf("Jira: %w", jiraapi.StatusError(resp))
 	}
 
-	var result map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	var result jiraResult
+	if err := httpclient.DecodeJSON(resp, httpclient.Jira, &result); err != nil {
 		return nil, err
 	}
 
-	// Simplify
-	issues := result["issues"].([]interface{})
-	var simplified []map[string]any
-	for _, i := range issues {
-		issue := i.(map[string]interface{})
-		fields := issue["fields"].(map[string]interface{})
-		simplified = append(simplified, map[string]any{
-			"key":     issue["key"],
-			"summary": fields["summary"],
-			"status":  fields["status"].(map[string]interface{})["name"],
-		})
-	}
+	return toolResponse(jiraIssues(baseURL, result))
+}
+
+func jiraSearchURL(baseURL string) string {
+	path := jiraapi.SearchPath(jiraapi.SearchAPI(baseURL))
+	return fmt.Sprintf("%s%s?jql=assignee=currentUser()&maxResults=5&fields=summary,status,assignee", baseURL, path)
+}
 
-	return map[string]any{"issues": simplified}, nil
+// jiraIssues maps a search on the site at baseURL to the shared schema.
+func jiraIssues(baseURL string, result jiraResult) issuesResult {
+	issues := issuesResult{Source: "jira", Issues: []toolIssue{}}
+	for _, i := range result.Issues {
+		issue := toolIssue{
+			ID:     i.Key,
+			Title:  i.Fields.Summary,
+			Status: i.Fields.Status.Name,
+			URL:    strings.TrimSuffix(baseURL, "/") + "/browse/" + i.Key,
+		}
+		if i.Fields.Assignee != nil {
+			issue.Assignee = i.Fields.Assignee.DisplayName
+		}
+		issues.Issues = append(issues.Issues, issue)
+	}
+	return issues
 }
 
 // Tool Definitions for Gemini
@@ -113,11 +190,11 @@ var tools = []*genai.Tool{
 		FunctionDeclarations: []*genai.FunctionDeclaration{
 			{
 				Name:        "get_github_issues",
-				Description: "Get list of open GitHub issues for the configured repository.",
+				Description: "Get list of open GitHub issues for the configured repository, each with id, title, status, url and assignee.",
 			},
 			{
 				Name:        "get_jira_issues",
-				Description: "Get list of Jira issues assigned to the current user.",
+				Description: "Get list of Jira issues assigned to the current user, each with id, title, status, url and assignee.",
 			},
 		},
 	},
@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGitHubIssuesURL(t *testing.T) {
+	tests := []struct {
+		repo string
+		want string
+	}{
+		{"owner/name", "https://api.github.com/repos/owner/name/issues?state=open&per_page=5"},
+		{"dhirajnikam/the-bridge", "https://api.github.com/repos/dhirajnikam/the-bridge/issues?state=open&per_page=5"},
+	}
+	for _, tt := range tests {
+		if got := githubIssuesURL(tt.repo); got != tt.want {
+			t.Errorf("githubIssuesURL(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestJiraSearchURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		api     string
+		want    string
+	}{
+		{"cloud", "https://acme.atlassian.net", "",
+			"https://acme.atlassian.net/rest/api/3/search/jql?jql=assignee=currentUser()&maxResults=5&fields=summary,status,assignee"},
+		{"self-hosted", "https://jira.example.com", "",
+			"https://jira.example.com/rest/api/3/search?jql=assignee=currentUser()&maxResults=5&fields=summary,status,assignee"},
+		{"forced legacy", "https://acme.atlassian.net", "legacy",
+			"https://acme.atlassian.net/rest/api/3/search?jql=assignee=currentUser()&maxResults=5&fields=summary,status,assignee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JIRA_SEARCH_API", tt.api)
+			if got := jiraSearchURL(tt.baseURL); got != tt.want {
+				t.Errorf("jiraSearchURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubResult(t *testing.T) {
+	var issues []githubIssue
+	err := json.Unmarshal([]byte(`[
+		{"number": 45, "title": "Crash on start", "state": "open", "html_url": "https://github.com/o/r/issues/45", "assignee": {"login": "ana"}},
+		{"number": 46, "title": "Typo", "state": "open", "html_url": "https://github.com/o/r/issues/46", "assignee": null}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		issues []githubIssue
+		want   issuesResult
+	}{
+		{"none", nil, issuesResult{Source: "github", Issues: []toolIssue{}}},
+		{"assigned and not", issues, issuesResult{Source: "github", Issues: []toolIssue{
+			{ID: "o/r#45", Title: "Crash on start", Status: "open", URL: "https://github.com/o/r/issues/45", Assignee: "ana"},
+			{ID: "o/r#46", Title: "Typo", Status: "open", URL: "https://github.com/o/r/issues/46"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := githubResult("o/r", tt.issues); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("githubResult = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJiraIssues(t *testing.T) {
+	var result jiraResult
+	err := json.Unmarshal([]byte(`{"issues": [
+		{"key": "PROJ-1", "fields": {"summary": "Login fails", "status": {"name": "In Progress"}, "assignee": {"displayName": "Ana"}}},
+		{"key": "PROJ-2", "fields": {"summary": "Docs", "status": {"name": "To Do"}, "assignee": null}}
+	]}`), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		baseURL string
+		result  jiraResult
+		want    issuesResult
+	}{
+		{"none", "https://jira.example.com", jiraResult{}, issuesResult{Source: "jira", Issues: []toolIssue{}}},
+		{"trailing slash", "https://jira.example.com/", result, issuesResult{Source: "jira", Issues: []toolIssue{
+			{ID: "PROJ-1", Title: "Login fails", Status: "In Progress", URL: "https://jira.example.com/browse/PROJ-1", Assignee: "Ana"},
+			{ID: "PROJ-2", Title: "Docs", Status: "To Do", URL: "https://jira.example.com/browse/PROJ-2"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jiraIssues(tt.baseURL, tt.result); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("jiraIssues = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
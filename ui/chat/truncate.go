@@ -0,0 +1,47 @@
+package chat
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -- Long Answers --
+
+// defaultMaxChars is how much of an answer is shown before the rest is cut
+// off, unless TERMIFLOW_CHAT_MAX_CHARS says otherwise.
+const defaultMaxChars = 20000
+
+func maxChars() int {
+	if n, err := strconv.Atoi(os.Getenv("TERMIFLOW_CHAT_MAX_CHARS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxChars
+}
+
+// truncated reports whether msg is an answer shown cut off.
+func truncated(msg Message) bool {
+	return msg.Role == "model" && !msg.Full && utf8.RuneCountInString(msg.Content) > maxChars()
+}
+
+// shownContent is the part of msg's answer that is displayed, ending at a
+// line break where one is near the limit, and how many characters were
+// left out. The message itself keeps the full text for copying and Full.
+func shownContent(msg Message) (string, int) {
+	if !truncated(msg) {
+		return msg.Content, 0
+	}
+	runes := []rune(msg.Content)
+	limit := maxChars()
+	shown := string(runes[:limit])
+	if i := strings.LastIndexByte(shown, '\n'); i > len(shown)*4/5 {
+		shown = shown[:i]
+	}
+	omitted := len(runes) - utf8.RuneCountInString(shown)
+	// Close a code block cut in half so the note doesn't render as code
+	if strings.Count(shown, "```")%2 == 1 {
+		shown += "\n```"
+	}
+	return shown, omitted
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"termiflow/clipboard"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var flashStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).MarginLeft(2)
+
+// flashDuration is how long a confirmation stays next to the tabs.
+const flashDuration = 2 * time.Second
+
+type flashDoneMsg struct{ id int }
+
+// lastError returns the active tab's most recent error. The dashboard has
+// none of its own, so it offers the first error from any tab.
+func (m Model) lastError() string {
+	errText := func(err error) string {
+		if err == nil {
+			return ""
+		}
+		return err.Error()
+	}
+	switch m.state {
+	case viewShell:
+		return m.shell.LastError()
+	case viewJira:
+		return errText(m.jira.Err())
+	case viewGitHub:
+		return errText(m.github.Err())
+	case viewChat:
+		return m.chat.LastError()
+	}
+	for _, e := range []string{m.shell.LastError(), errText(m.jira.Err()), errText(m.github.Err()), m.chat.LastError()} {
+		if e != "" {
+			return e
+		}
+	}
+	return ""
+}
+
+// copyLastError puts the active tab's last error on the clipboard.
+func (m *Model) copyLastError() tea.Cmd {
+	e := m.lastError()
+	if e == "" {
+		return m.setFlash("No error to copy")
+	}
+	if err := clipboard.Copy(e); err != nil {
+		return m.setFlash(fmt.Sprintf("Could not copy: %v", err))
+	}
+	return m.setFlash("Error copied")
+}
+
+// maxTranscriptCopy is the largest shell transcript put on the clipboard;
+// longer ones are written to a file, since clipboards (and OSC 52 over SSH)
+// choke on big pastes.
+const maxTranscriptCopy = 100 << 10
+
+// copyTranscript copies the whole shell session, or saves it to a temp file
+// when it is too big to paste.
+func (m *Model) copyTranscript() tea.Cmd {
+	text := m.shell.Transcript()
+	if len(text) <= maxTranscriptCopy {
+		if err := clipboard.Copy(text); err != nil {
+			return m.setFlash(fmt.Sprintf("Could not copy: %v", err))
+		}
+		return m.setFlash("Session transcript copied")
+	}
+	f, err := os.CreateTemp("", "termiflow-transcript-*.txt")
+	if err == nil {
+		_, err = f.WriteString(text)
+		err = errors.Join(err, f.Close())
+	}
+	if err != nil {
+		return m.setFlash(fmt.Sprintf("Could not save transcript: %v", err))
+	}
+	return m.setFlash("Transcript saved to " + f.Name())
+}
+
+// setFlash shows text next to the tabs until flashDuration passes or
+// another flash replaces it.
+func (m *Model) setFlash(text string) tea.Cmd {
+	m.flash = text
+	m.flashID++
+	id := m.flashID
+	return tea.Tick(flashDuration, func(time.Time) tea.Msg { return flashDoneMsg{id} })
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+
+	"termiflow/ui/dashboard"
+	"termiflow/ui/issuestate"
+)
+
+const dashboardTopItems = 5
+
+// dashboardPanels summarizes each sub-model's current state for the
+// dashboard tab. The data comes from the sub-models' own fetches.
+func (m Model) dashboardPanels() []dashboard.Panel {
+	return []dashboard.Panel{m.jiraPanel(), m.githubPanel(), m.chatPanel()}
+}
+
+func (m Model) jiraPanel() dashboard.Panel {
+	p := dashboard.Panel{Title: "Jira"}
+	issues := m.jira.Issues()
+	switch {
+	case m.jira.Err() != nil:
+		p.Status = fmt.Sprintf("Error: %v", m.jira.Err())
+	case m.jira.Loading():
+		p.Status = "Loading…"
+	case issues == nil:
+		p.Status = "Not configured (set JIRA_URL and JIRA_TOKEN)"
+	default:
+		p.Status = fmt.Sprintf("%d %s", len(issues), m.jira.Shown())
+	}
+	for i, issue := range issues {
+		if i == dashboardTopItems {
+			break
+		}
+		p.Lines = append(p.Lines, fmt.Sprintf("%s %s [%s]", issue.Key, issue.Fields.Summary, issue.Fields.Status.Name))
+	}
+	return p
+}
+
+func (m Model) githubPanel() dashboard.Panel {
+	p := dashboard.Panel{Title: "GitHub · " + m.github.Repo()}
+	issues := m.github.Issues()
+	switch {
+	case m.github.Err() != nil:
+		p.Status = fmt.Sprintf("Error: %v", m.github.Err())
+	case m.github.Loading():
+		p.Status = "Loading…"
+	default:
+		prs := 0
+		for _, issue := range issues {
+			if issue.PullRequest != nil {
+				prs++
+			}
+		}
+		state := m.github.State().String() + " "
+		if m.github.State() == issuestate.All {
+			state = ""
+		}
+		p.Status = fmt.Sprintf("%d %sissues, %d %sPRs", len(issues)-prs, state, prs, state)
+	}
+	for i, issue := range issues {
+		if i == dashboardTopItems {
+			break
+		}
+		ref := fmt.Sprintf("#%d", issue.Number)
+		if len(m.github.Repos()) > 1 {
+			ref = issue.Repo + ref
+		}
+		p.Lines = append(p.Lines, fmt.Sprintf("%s %s", ref, issue.Title))
+	}
+	return p
+}
+
+func (m Model) chatPanel() dashboard.Panel {
+	p := dashboard.Panel{Title: "Chat"}
+	msgs := m.chat.Messages()
+	if len(msgs) == 0 {
+		p.Status = "No messages yet"
+		return p
+	}
+	p.Status = fmt.Sprintf("%d messages this session", len(msgs))
+
+	// Most recent first
+	for i := len(msgs) - 1; i >= 0 && len(p.Lines) < 3; i-- {
+		who := "Gemini"
+		text := msgs[i].Content
+		switch msgs[i].Role {
+		case "user":
+			who = "You"
+		case "system":
+			who = "System"
+		case "tool":
+			who, text = "Tool", msgs[i].Summary
+		}
+		p.Lines = append(p.Lines, fmt.Sprintf("%s: %s", who, text))
+	}
+	return p
+}
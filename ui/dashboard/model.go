@@ -0,0 +1,87 @@
+package dashboard
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	panelStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}).
+			Padding(0, 1)
+	panelTitleStyle  = lipgloss.NewStyle().Bold(true)
+	panelStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// -- Data Structures --
+
+// Panel is one integration's summary. The root model builds panels from the
+// sub-models' state, so the dashboard never fetches anything itself.
+type Panel struct {
+	Title  string
+	Status string   // e.g. "5 open issues (mine)" or an error
+	Lines  []string // top items, most relevant first
+}
+
+// -- Model --
+
+type Model struct {
+	width  int
+	height int
+}
+
+func New() Model {
+	return Model{}
+}
+
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m Model) View(panels []Panel) string {
+	if len(panels) == 0 {
+		return ""
+	}
+
+	// Side by side when there's room, stacked otherwise
+	horizontal := m.width >= 30*len(panels)
+	width := m.width - 4
+	if horizontal {
+		width = m.width/len(panels) - 4
+	}
+	if width < 10 {
+		width = 10
+	}
+
+	var rendered []string
+	for _, p := range panels {
+		rendered = append(rendered, renderPanel(p, width))
+	}
+	if horizontal {
+		return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...)
+}
+
+func renderPanel(p Panel, width int) string {
+	var sb strings.Builder
+	sb.WriteString(panelTitleStyle.Render(p.Title))
+	sb.WriteString("\n")
+	sb.WriteString(panelStatusStyle.Render(p.Status))
+	for _, line := range p.Lines {
+		sb.WriteString("\n• ")
+		sb.WriteString(truncate(strings.Join(strings.Fields(line), " "), width-2))
+	}
+	return panelStyle.Width(width).Render(sb.String())
+}
+
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width < 1 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
@@ -0,0 +1,113 @@
+package delegate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// titleLines is how many lines a wrapped title may take.
+const titleLines = 2
+
+// New returns the delegate for the Jira and GitHub lists. Titles wider than
+// the list end in an ellipsis; with TERMIFLOW_LIST_WRAP=1 they wrap onto a
+// second line first, and every item grows a line to make room.
+func New() list.ItemDelegate {
+	d := list.NewDefaultDelegate()
+	if os.Getenv("TERMIFLOW_LIST_WRAP") != "1" {
+		return accenting{d}
+	}
+	d.SetHeight(titleLines + 1)
+	return wrapping{d}
+}
+
+// Accented is an item that colors part of its description, like a Jira
+// status. Description stays plain and the delegate draws the accent, so its
+// reset doesn't end the description's own style partway.
+type Accented interface {
+	list.DefaultItem
+	// Accent returns the byte range of Description to color and its style.
+	Accent() (from, to int, style lipgloss.Style)
+}
+
+// accenting is the default delegate plus accents.
+type accenting struct{ list.DefaultDelegate }
+
+func (d accenting) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	d.DefaultDelegate.Render(w, m, index, accented(&d.Styles, m, index, item))
+}
+
+// accented returns item with its accent drawn into the description over
+// the style the description gets, or item itself when it has none.
+func accented(s *list.DefaultItemStyles, m list.Model, index int, item list.Item) list.Item {
+	a, ok := item.(Accented)
+	if !ok {
+		return item
+	}
+	desc := a.Description()
+	from, to, style := a.Accent()
+	if from < 0 || from >= to || to > len(desc) {
+		return item
+	}
+	_, base := styles(s, m, index)
+	base = base.Inline(true)
+	return styledItem{a, base.Render(desc[:from]) + style.Inherit(base).Inline(true).Render(desc[from:to]) + base.Render(desc[to:])}
+}
+
+// styledItem replaces an item's description with a styled one.
+type styledItem struct {
+	list.DefaultItem
+	desc string
+}
+
+func (i styledItem) Description() string { return i.desc }
+
+// styles picks the title and description styles for the item at index, as
+// the default delegate does.
+func styles(s *list.DefaultItemStyles, m list.Model, index int) (title, desc lipgloss.Style) {
+	switch {
+	case m.FilterState() == list.Filtering && m.FilterValue() == "":
+		return s.DimmedTitle, s.DimmedDesc
+	case index == m.Index() && m.FilterState() != list.Filtering:
+		return s.SelectedTitle, s.SelectedDesc
+	}
+	return s.NormalTitle, s.NormalDesc
+}
+
+// wrapping draws titles over titleLines lines. It keeps the default
+// delegate's styles but not its filter-match highlighting, which can't
+// follow a title across lines.
+type wrapping struct{ list.DefaultDelegate }
+
+func (d wrapping) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	s := &d.Styles
+	i, ok := accented(s, m, index, item).(list.DefaultItem)
+	if !ok || m.Width() <= 0 {
+		return
+	}
+	width := max(m.Width()-s.NormalTitle.GetPaddingLeft()-s.NormalTitle.GetPaddingRight(), 1)
+	title := wrapTitle(i.Title(), width)
+	desc := ansi.Truncate(strings.SplitN(i.Description(), "\n", 2)[0], width, "…")
+	titleStyle, descStyle := styles(s, m, index)
+	fmt.Fprintf(w, "%s\n%s", titleStyle.Render(title), descStyle.Render(desc))
+}
+
+// wrapTitle wraps title to width, cutting the last line short with an
+// ellipsis when it needs more than titleLines. Short titles are padded so
+// every item has the same height.
+func wrapTitle(title string, width int) string {
+	lines := strings.Split(ansi.Wrap(title, width, " -"), "\n")
+	if len(lines) > titleLines {
+		rest := strings.Join(lines[titleLines-1:], " ")
+		lines = append(lines[:titleLines-1], ansi.Truncate(rest, width, "…"))
+	}
+	for len(lines) < titleLines {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,43 @@
+package delegate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestWrapTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		width int
+		want  string
+	}{
+		{"fits", "Fix login", 20, "Fix login\n"},
+		{"wraps at a space", "Fix the login page", 10, "Fix the\nlogin page"},
+		{"wraps at a hyphen", "cross-platform build", 12, "cross-\nplatform bu…"},
+		{"cut with an ellipsis", "Fix the login page on mobile", 10, "Fix the\nlogin pag…"},
+		{"long word broken", "abcdefghijklmnop", 6, "abcdef\nghijk…"},
+		{"wide runes", "日本語のタイトルです", 8, "日本語の\nタイト…"}, // タイトル… would take 9 cells,
+		{"wide rune not split", "日本語", 5, "日本\n語"},
+		{"emoji", "🚀 Launch the rocket", 8, "🚀\nLaunch …"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapTitle(tt.title, tt.width)
+			if got != tt.want {
+				t.Errorf("wrapTitle(%q, %d) = %q, want %q", tt.title, tt.width, got, tt.want)
+			}
+			lines := strings.Split(got, "\n")
+			if len(lines) != titleLines {
+				t.Errorf("got %d lines, want %d", len(lines), titleLines)
+			}
+			for _, line := range lines {
+				if w := ansi.StringWidth(line); w > tt.width {
+					t.Errorf("line %q is %d cells wide, want at most %d", line, w, tt.width)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package detail is the full-screen issue view shared by the Jira and
+// GitHub tabs: a bold title, a meta line, the body rendered as Markdown in
+// a scrolling viewport and a footer listing the keys.
+package detail
+
+import (
+	"fmt"
+	"strings"
+
+	"termiflow/mdrender"
+	"termiflow/ui/layout"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// chrome is the lines around the viewport: the vertical margin, the title,
+// the meta line and the footer.
+const chrome = 5
+
+type Model struct {
+	title    string
+	meta     string // styled by the tab
+	markdown string
+	vp       viewport.Model
+	width    int
+}
+
+// New returns the view for an issue, sized to the tab.
+func New(title, meta, markdown string, width, height int) Model {
+	m := Model{title: title, meta: meta, markdown: markdown, vp: viewport.New(0, 0)}
+	m.SetSize(width, height)
+	return m
+}
+
+// SetSize fits the view to the tab; glamour wraps the body itself, so it's
+// re-rendered.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.vp.Width, m.vp.Height = max(width-layout.Margin, 0), max(height-chrome, 1)
+	body := strings.TrimSpace(m.markdown)
+	if body == "" {
+		m.vp.SetContent(statusStyle.Render("No description."))
+		return
+	}
+	m.vp.SetContent(strings.Trim(mdrender.Render(body, max(width-layout.Margin, 20)), "\n"))
+}
+
+// SetMeta replaces the line under the title, e.g. once an avatar arrives.
+func (m *Model) SetMeta(meta string) { m.meta = meta }
+
+// Update scrolls the body.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.vp, cmd = m.vp.Update(msg)
+	return m, cmd
+}
+
+// View draws the issue. keys lists the tab's own keys for the footer and
+// note, when set, leads it (the result of the last key, say).
+func (m Model) View(keys, note string) string {
+	title := lipgloss.NewStyle().Bold(true).Render(m.title)
+	footer := fmt.Sprintf("%3.f%% • ↑/↓ pgup/pgdn scroll • %s • esc back", m.vp.ScrollPercent()*100, keys)
+	footer = statusStyle.Render(footer)
+	if note != "" {
+		footer = note + statusStyle.Render(" • ") + footer
+	}
+	body := lipgloss.NewStyle().MaxWidth(max(m.width-layout.Margin, 0)).Render(title + "\n" + m.meta + "\n" + m.vp.View())
+	return layout.Frame.Render(body + "\n" + footer)
+}
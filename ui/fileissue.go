@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"termiflow/ui/shell"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// issueOutputTail is how many lines of output go into a filed issue; the
+// start of a long log rarely matters and GitHub caps the body size.
+const issueOutputTail = 200
+
+// issueTitleLimit keeps long command lines from making unwieldy titles.
+const issueTitleLimit = 80
+
+// fileLastCommand opens the GitHub create-issue form pre-filled with the
+// shell's last command and its output, and switches to the GitHub tab.
+func (m *Model) fileLastCommand() tea.Cmd {
+	run, ok := m.shell.LastRun()
+	if !ok {
+		return m.setFlash("No command to file")
+	}
+	cmd, err := m.github.NewIssue(runIssue(run))
+	if err != nil {
+		return m.setFlash(err.Error())
+	}
+	m.state = viewGitHub
+	return cmd
+}
+
+// runIssue drafts an issue title and markdown body for a command run.
+func runIssue(run shell.Run) (title, body string) {
+	command := run.Command
+	if r := []rune(command); len(r) > issueTitleLimit {
+		command = string(r[:issueTitleLimit]) + "…"
+	}
+	if run.Err != nil {
+		title = fmt.Sprintf("`%s` fails: %v", command, run.Err)
+	} else {
+		title = fmt.Sprintf("Output of `%s`", command)
+	}
+
+	lines := strings.Split(strings.TrimRight(run.Output, "\n"), "\n")
+	if len(lines) > issueOutputTail {
+		lines = append([]string{fmt.Sprintf("… (%d earlier lines omitted)", len(lines)-issueOutputTail)}, lines[len(lines)-issueOutputTail:]...)
+	}
+	output := strings.Join(lines, "\n")
+
+	// Use a fence longer than any backtick run in the output
+	fence := "```"
+	for strings.Contains(output+"\n"+run.Command, fence) {
+		fence += "`"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Command:\n\n%s\n$ %s\n%s\n\n", fence, run.Command, fence)
+	fmt.Fprintf(&sb, "Output:\n\n%s\n%s\n%s\n", fence, output, fence)
+	if run.Err != nil {
+		fmt.Fprintf(&sb, "\nError: %v\n", run.Err)
+	}
+	return title, sb.String()
+}
@@ -0,0 +1,49 @@
+// Package focus is how tabs move keyboard focus between their parts. A
+// part implements Focusable, as the textinput and textarea bubbles already
+// do; the focused one gets the keys and tab/shift+tab cycle through them.
+// Tabs themselves are Focusable too, so the root model can tell the one
+// being shown.
+package focus
+
+import tea "github.com/charmbracelet/bubbletea"
+
+type Focusable interface {
+	Focus() tea.Cmd
+	Blur()
+	Focused() bool
+}
+
+// Pane makes a part without a cursor of its own, like a scrolling view,
+// focusable.
+type Pane struct{ focused bool }
+
+func (p *Pane) Focus() tea.Cmd { p.focused = true; return nil }
+func (p *Pane) Blur()          { p.focused = false }
+func (p Pane) Focused() bool   { return p.focused }
+
+// Set focuses target and blurs the other parts.
+func Set(target Focusable, parts ...Focusable) tea.Cmd {
+	for _, p := range parts {
+		if p != target {
+			p.Blur()
+		}
+	}
+	return target.Focus()
+}
+
+// Cycle moves focus to the part after the focused one, or before it when
+// back, wrapping around. With none focused the first part gets it.
+func Cycle(back bool, parts ...Focusable) tea.Cmd {
+	next := 0
+	for i, p := range parts {
+		if p.Focused() {
+			step := 1
+			if back {
+				step = len(parts) - 1
+			}
+			next = (i + step) % len(parts)
+			break
+		}
+	}
+	return Set(parts[next], parts...)
+}
@@ -0,0 +1,33 @@
+package focus
+
+import "testing"
+
+func TestCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused int // -1 for none
+		back    bool
+		want    int
+	}{
+		{"forward", 0, false, 1},
+		{"forward wraps", 2, false, 0},
+		{"back", 1, true, 0},
+		{"back wraps", 0, true, 2},
+		{"none focused", -1, false, 0},
+		{"none focused going back", -1, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := []Focusable{&Pane{}, &Pane{}, &Pane{}}
+			if tt.focused >= 0 {
+				parts[tt.focused].Focus()
+			}
+			Cycle(tt.back, parts...)
+			for i, p := range parts {
+				if p.Focused() != (i == tt.want) {
+					t.Errorf("part %d focused = %v, want only part %d", i, p.Focused(), tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/ui/focus"
+	"termiflow/ui/layout"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- New Issue Form --
+
+var (
+	formLabelStyle = lipgloss.NewStyle().Bold(true)
+	formErrStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+)
+
+// bodyLimit stays under GitHub's 65536 character limit for issue bodies.
+const bodyLimit = 60000
+
+// errNoToken is returned when creating an issue without GITHUB_TOKEN, since
+// unauthenticated requests can only read.
+var errNoToken = errors.New("GITHUB_TOKEN is required to create issues")
+
+// issueForm collects the title and body of an issue to file in repo.
+type issueForm struct {
+	repo       string
+	title      textinput.Model
+	body       textarea.Model
+	submitting bool
+	err        error
+}
+
+type issueCreatedMsg struct{ issue GitHubIssue }
+type createErrMsg struct{ err error }
+
+// NewIssue opens the create-issue form pre-filled with title and body. The
+// issue goes to the repo the list is narrowed to or the highlighted one.
+func (m *Model) NewIssue(title, body string) (tea.Cmd, error) {
+	if config.Secret("GITHUB_TOKEN") == "" && !demo.Enabled() {
+		return nil, errNoToken
+	}
+	repo := m.repos[0]
+	if m.only != "" {
+		repo = m.only
+	} else if issue, ok := m.SelectedIssue(); ok {
+		repo = issue.Repo
+	}
+
+	ti := textinput.New()
+	ti.Placeholder = "Title"
+	ti.SetValue(title)
+
+	ta := textarea.New()
+	ta.Placeholder = "Describe the issue (markdown)"
+	ta.ShowLineNumbers = false
+	ta.CharLimit = bodyLimit
+	ta.SetValue(body)
+	ta.Blur()
+
+	m.form = &issueForm{repo: repo, title: ti, body: ta}
+	m.sizeForm()
+	return m.form.title.Focus(), nil
+}
+
+// Editing reports whether the create-issue form, a diff, an issue or the
+// search prompt is open, so keys belong to it.
+func (m Model) Editing() bool {
+	return m.form != nil || m.diff != nil || m.detail != nil || m.prompt != nil
+}
+
+func (m *Model) sizeForm() {
+	if m.form == nil {
+		return
+	}
+	m.form.title.Width = max(m.width-10, 10)
+	m.form.body.SetWidth(max(m.width-4, 10))
+	m.form.body.SetHeight(max(m.height-10, 3))
+}
+
+// updateForm handles keys while the form is open: tab/shift+tab switch
+// between title and body, ctrl+s submits and esc discards the draft.
+func (m Model) updateForm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	f := m.form
+	if f.submitting {
+		return m, nil
+	}
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		return m, m.list.NewStatusMessage("Issue discarded")
+	case "ctrl+s":
+		title := strings.TrimSpace(f.title.Value())
+		if title == "" {
+			f.err = errors.New("title is required")
+			return m, nil
+		}
+		f.submitting = true
+		f.err = nil
+		return m, createIssue(f.repo, title, f.body.Value())
+	case "tab", "shift+tab", "enter":
+		if msg.String() == "enter" && !f.title.Focused() {
+			break // newline in the body
+		}
+		return m, focus.Cycle(msg.String() == "shift+tab", &f.title, &f.body)
+	}
+
+	var cmd tea.Cmd
+	if f.title.Focused() {
+		f.title, cmd = f.title.Update(msg)
+	} else {
+		f.body, cmd = f.body.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m Model) formView() string {
+	f := m.form
+	var sb strings.Builder
+	sb.WriteString(formLabelStyle.Render("New issue in "+f.repo) + "\n\n")
+	sb.WriteString(f.title.View() + "\n\n")
+	sb.WriteString(f.body.View() + "\n\n")
+	switch {
+	case f.submitting:
+		sb.WriteString(statusStyle.Render("Creating issue…"))
+	case f.err != nil:
+		sb.WriteString(formErrStyle.Render(fmt.Sprintf("Error: %v", f.err)))
+	default:
+		sb.WriteString(statusStyle.Render("tab switch field • ctrl+s create • esc discard"))
+	}
+	return layout.Frame.Render(sb.String())
+}
+
+// createIssue files a new issue in repo.
+func createIssue(repo, title, body string) tea.Cmd {
+	return func() tea.Msg {
+		if demo.Enabled() {
+			return createErrMsg{errors.New("creating issues is disabled in demo mode")}
+		}
+		token := config.Secret("GITHUB_TOKEN")
+		if token == "" {
+			return createErrMsg{errNoToken}
+		}
+
+		payload, err := json.Marshal(map[string]string{"title": title, "body": body})
+		if err != nil {
+			return createErrMsg{err}
+		}
+		req, err := http.NewRequest("POST", fmt.Sprintf("https://api.github.com/repos/%s/issues", repo), bytes.NewReader(payload))
+		if err != nil {
+			return createErrMsg{err}
+		}
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("Authorization", "Bearer "+token)
+		req.Header.Add("Content-Type", "application/json")
+		httpclient.SetHeaders(req, httpclient.GitHub)
+
+		resp, err := httpclient.New(10 * time.Second).Do(req)
+		if err != nil {
+			return createErrMsg{err}
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			return createErrMsg{fmt.Errorf("API Error: %s", resp.Status)}
+		}
+		var issue GitHubIssue
+		if err := httpclient.DecodeJSON(resp, httpclient.GitHub, &issue); err != nil {
+			return createErrMsg{err}
+		}
+		issue.Repo = repo
+		return issueCreatedMsg{issue}
+	}
+}
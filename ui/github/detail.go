@@ -0,0 +1,166 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"termiflow/browser"
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/termimage"
+	"termiflow/ui/detail"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Issue Detail (enter) --
+
+type detailView struct {
+	issue  GitHubIssue
+	view   detail.Model
+	err    error
+	avatar string // the author's picture as an inline image, "" until fetched
+}
+
+// avatarCols is how many cells wide the author's avatar is drawn; at two
+// cells a square picture fits on the meta line.
+const avatarCols = 2
+
+// maxAvatarBytes bounds an avatar download; GitHub serves 64px ones in a
+// few KB.
+const maxAvatarBytes = 256 << 10
+
+type avatarFetchedMsg struct {
+	url      string
+	data     []byte
+	mimeType string
+}
+
+// openDetail shows the selected issue with its body rendered as Markdown.
+func (m *Model) openDetail() tea.Cmd {
+	issue, ok := m.SelectedIssue()
+	if !ok {
+		return nil
+	}
+	title := fmt.Sprintf("%s#%d %s", issue.Repo, issue.Number, issue.Title)
+	d := &detailView{issue: issue}
+	d.view = detail.New(title, d.meta(), issue.Body, m.width, m.height)
+	m.detail = d
+	if termimage.Detect() == termimage.None || issue.User.AvatarURL == "" || demo.Enabled() {
+		return nil
+	}
+	return fetchAvatar(issue.User.AvatarURL)
+}
+
+// fetchAvatar downloads a small copy of an avatar. Failures are dropped:
+// the detail view just goes without the picture.
+func fetchAvatar(avatarURL string) tea.Cmd {
+	return func() tea.Msg {
+		msg := avatarFetchedMsg{url: avatarURL}
+		u, err := url.Parse(avatarURL)
+		if err != nil {
+			return msg
+		}
+		q := u.Query()
+		q.Set("s", "64")
+		u.RawQuery = q.Encode()
+		req, _ := http.NewRequest("GET", u.String(), nil)
+		httpclient.SetHeaders(req, httpclient.GitHub)
+		resp, err := httpclient.New(10 * time.Second).Do(req)
+		if err != nil {
+			return msg
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return msg
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarBytes))
+		if err != nil {
+			return msg
+		}
+		msg.data, msg.mimeType = data, resp.Header.Get("Content-Type")
+		return msg
+	}
+}
+
+func (d *detailView) url() string {
+	kind := "issues"
+	if d.issue.PullRequest != nil {
+		kind = "pull"
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/%d", d.issue.Repo, kind, d.issue.Number)
+}
+
+// updateDetail handles keys while an issue is open: the viewport scrolls,
+// o opens it in the browser, d shows a pull request's diff and esc goes
+// back to the list.
+func (m Model) updateDetail(msg tea.KeyMsg) (Model, tea.Cmd) {
+	d := m.detail
+	switch msg.String() {
+	case "esc", "q":
+		m.detail = nil
+		return m, nil
+	case "o":
+		if err := browser.Open(d.url()); err != nil {
+			d.err = fmt.Errorf("could not open browser: %w", err)
+		}
+		return m, nil
+	case "d":
+		if d.issue.PullRequest != nil {
+			m.detail = nil
+			return m, m.openDiff()
+		}
+	}
+	var cmd tea.Cmd
+	d.view, cmd = d.view.Update(msg)
+	return m, cmd
+}
+
+// meta is the line under the title: state, author (with the avatar once
+// it's fetched), date, comments and labels.
+func (d *detailView) meta() string {
+	issue := d.issue
+	meta := fmt.Sprintf("%s • opened by %s on %s • %d comments", issue.State, issue.User.Login, issue.CreatedAt.Format("2006-01-02"), issue.Comments)
+	if len(issue.Labels) > 0 {
+		meta += " " + labelChips(issue.Labels)
+	}
+	meta = statusStyle.Render(meta)
+	if d.avatar != "" {
+		meta = d.avatar + " " + meta
+	}
+	return meta
+}
+
+func (m Model) detailView() string {
+	d := m.detail
+	keys := "o open in browser"
+	if d.issue.PullRequest != nil {
+		keys += " • d diff"
+	}
+	note := ""
+	if d.err != nil {
+		note = formErrStyle.Render(d.err.Error())
+	}
+	return d.view.View(keys, note)
+}
+
+// ShowIssue selects issue number of repo and opens it, clearing any filter
+// first. Chat references land here.
+func (m *Model) ShowIssue(repo string, number int) tea.Cmd {
+	m.list.ResetFilter()
+	if m.only != "" && m.only != repo {
+		m.only = ""
+		m.setItems()
+	}
+	for i, it := range m.list.Items() {
+		if it, ok := it.(item); ok && strings.EqualFold(it.repo, repo) && it.number == number {
+			m.list.Select(i)
+			return m.openDetail()
+		}
+	}
+	return m.list.NewStatusMessage(fmt.Sprintf("%s#%d is not an open issue here; press o in chat to open it", repo, number))
+}
@@ -0,0 +1,158 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"termiflow/browser"
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/ui/layout"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Pull Request Diff (d) --
+
+var (
+	diffAddStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5FD75F"))
+	diffDelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	diffHunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00AFFF"))
+	diffFileStyle = lipgloss.NewStyle().Bold(true)
+)
+
+// maxDiffBytes caps what's fetched; bigger diffs are cut off with a hint
+// to open the PR in the browser.
+const maxDiffBytes = 512 << 10
+
+type diffView struct {
+	repo      string
+	number    int
+	vp        viewport.Model
+	loading   bool
+	truncated bool
+	err       error
+}
+
+type diffFetchedMsg struct {
+	repo      string
+	number    int
+	diff      string
+	truncated bool
+	err       error
+}
+
+// openDiff fetches the selected pull request's diff. Plain issues have none.
+func (m *Model) openDiff() tea.Cmd {
+	issue, ok := m.SelectedIssue()
+	if !ok || issue.PullRequest == nil {
+		return m.list.NewStatusMessage("Not a pull request")
+	}
+	vp := viewport.New(max(m.width-layout.Margin, 0), max(m.height-4, 1))
+	m.diff = &diffView{repo: issue.Repo, number: issue.Number, vp: vp, loading: true}
+	return fetchDiff(issue.Repo, issue.Number)
+}
+
+func fetchDiff(repo string, number int) tea.Cmd {
+	return func() tea.Msg {
+		msg := diffFetchedMsg{repo: repo, number: number}
+		if demo.Enabled() {
+			msg.err = errors.New("diffs are not available in demo mode")
+			return msg
+		}
+		req, _ := http.NewRequest("GET", fmt.Sprintf("https://api.github.com/repos/%s/pulls/%d", repo, number), nil)
+		req.Header.Add("Accept", "application/vnd.github.v3.diff")
+		if token := config.Secret("GITHUB_TOKEN"); token != "" {
+			req.Header.Add("Authorization", "Bearer "+token)
+		}
+		httpclient.SetHeaders(req, httpclient.GitHub)
+		resp, err := httpclient.New(30 * time.Second).Do(req)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			msg.err = fmt.Errorf("API Error: %s", resp.Status)
+			return msg
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxDiffBytes+1))
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		if len(data) > maxDiffBytes {
+			data = data[:maxDiffBytes]
+			msg.truncated = true
+		}
+		msg.diff = string(data)
+		return msg
+	}
+}
+
+// highlightDiff colors added, removed, hunk and file header lines.
+func highlightDiff(diff string) string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = diffFileStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffDelStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateDiff handles keys while the diff is open: the viewport scrolls,
+// o opens the PR in the browser and esc goes back to the list.
+func (m Model) updateDiff(msg tea.KeyMsg) (Model, tea.Cmd) {
+	d := m.diff
+	switch msg.String() {
+	case "esc", "q":
+		m.diff = nil
+		return m, nil
+	case "o":
+		url := fmt.Sprintf("https://github.com/%s/pull/%d/files", d.repo, d.number)
+		if err := browser.Open(url); err != nil {
+			d.err = fmt.Errorf("could not open browser: %w", err)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	d.vp, cmd = d.vp.Update(msg)
+	return m, cmd
+}
+
+func (m Model) diffView() string {
+	d := m.diff
+	title := lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%s#%d diff", d.repo, d.number))
+	var body, footer string
+	switch {
+	case d.loading:
+		body = "Loading diff…"
+	case d.err != nil && d.vp.TotalLineCount() == 0:
+		body = formErrStyle.Render(fmt.Sprintf("Error: %v", d.err))
+	default:
+		body = d.vp.View()
+	}
+	footer = fmt.Sprintf("%3.f%% • ↑/↓ pgup/pgdn scroll • o open in browser • esc back", d.vp.ScrollPercent()*100)
+	if d.truncated {
+		footer = fmt.Sprintf("Diff cut off at %d KB; press o to see all of it • ", maxDiffBytes>>10) + footer
+	}
+	if d.err != nil && d.vp.TotalLineCount() > 0 {
+		footer = formErrStyle.Render(d.err.Error()) + " • " + footer
+	}
+	return layout.Frame.Render(title + "\n" + body + "\n" + statusStyle.Render(footer))
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Label Chips --
+
+// Label is an issue label; Color is a hex RGB value without the "#".
+type Label struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// maxChips caps the labels drawn per issue; the rest are counted.
+const maxChips = 3
+
+// labelChips renders labels as chips in their GitHub colors.
+func labelChips(labels []Label) string {
+	chips := make([]string, 0, min(len(labels), maxChips)+1)
+	for _, l := range labels[:min(len(labels), maxChips)] {
+		chips = append(chips, chipStyle(l.Color).Render(l.Name))
+	}
+	if extra := len(labels) - maxChips; extra > 0 {
+		chips = append(chips, statusStyle.Render(fmt.Sprintf("+%d", extra)))
+	}
+	return strings.Join(chips, " ")
+}
+
+// chipStyle fills a chip with the label's color and picks black or white
+// text, whichever reads better on it, like the GitHub web UI does.
+func chipStyle(hex string) lipgloss.Style {
+	style := lipgloss.NewStyle().Padding(0, 1)
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return style.Reverse(true)
+	}
+	r, g, b := float64(rgb>>16&0xff), float64(rgb>>8&0xff), float64(rgb&0xff)
+	fg := "#FFFFFF"
+	if (0.299*r+0.587*g+0.114*b)/255 > 0.6 {
+		fg = "#000000"
+	}
+	return style.Background(lipgloss.Color("#" + hex)).Foreground(lipgloss.Color(fg))
+}
@@ -1,138 +1,552 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"termiflow/browser"
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/refresh"
+	"termiflow/termimage"
+	"termiflow/ui/delegate"
+	"termiflow/ui/issuestate"
+	"termiflow/ui/layout"
+
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+var (
+	statusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	newBadgeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF8700")).Bold(true)
+)
+
 // -- Data Structures --
 
 type GitHubIssue struct {
 	Number int    `json:"number"`
 	Title  string `json:"title"`
 	State  string `json:"state"`
+	Body   string `json:"body"`
 	User   struct {
-		Login string `json:"login"`
+		Login     string `json:"login"`
+		AvatarURL string `json:"avatar_url"`
 	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Comments  int       `json:"comments"`
+	Labels    []Label   `json:"labels"`
+	// Set when the "issue" is actually a pull request
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request,omitempty"`
+	Repo string `json:"-"` // owner/name it was fetched from
 }
 
 type item struct {
-	title string
-	desc  string
+	repo   string
+	number int // 0 for placeholder items
+	title  string
+	desc   string
+	labels []Label
+	isNew  bool // appeared since the user last viewed the tab
+}
+
+func (i item) Title() string {
+	if i.isNew {
+		return newBadgeStyle.Render("● NEW") + " " + i.title
+	}
+	return i.title
+}
+func (i item) Description() string {
+	if len(i.labels) == 0 {
+		return i.desc
+	}
+	return i.desc + " " + labelChips(i.labels)
 }
 
-func (i item) Title() string       { return i.title }
-func (i item) Description() string { return i.desc }
-func (i item) FilterValue() string { return i.title }
+// FilterValue lets the filter match label names as well as the title.
+func (i item) FilterValue() string {
+	names := make([]string, len(i.labels))
+	for j, l := range i.labels {
+		names[j] = l.Name
+	}
+	return strings.TrimSpace(i.title + " " + strings.Join(names, " "))
+}
 
 // -- Model --
 
 type Model struct {
-	list    list.Model
-	loading bool
-	err     error
+	list     list.Model
+	repos    []string // owner/name, from GITHUB_REPOS or the detected repo
+	issues   []GitHubIssue
+	failures []repoError // repos whose last fetch failed while others worked
+	only     string      // repo the list is narrowed to, "" for all
+	sort     issueSort
+	state    issuestate.State
+	loading  bool
+	err      error
+	count    int           // fetched issues, excluding placeholder items
+	took     time.Duration // how long the last fetch took
+
+	seen    map[string]map[int]bool // per repo, issue numbers already viewed
+	unseen  int                     // new issues not yet viewed, shown on the tab
+	focused bool                    // the GitHub tab is showing; see Focus
+
+	poller    *refresh.Poller   // auto-refresh, shared by copies
+	inflight  *refresh.Inflight // the latest issue fetch, likewise
+	searching *refresh.Inflight // the latest search page, likewise
+
+	search *search          // issue search shown instead of the repos, nil when listing
+	prompt *textinput.Model // search query prompt, nil when closed
+
+	form          *issueForm  // create-issue form, nil when closed
+	diff          *diffView   // pull request diff, nil when closed
+	detail        *detailView // issue body, nil when closed
+	width, height int
 }
 
 func New() Model {
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	l.Title = "GitHub Issues (charmbracelet/bubbletea)"
+	repos := ConfiguredRepos()
+	label := strings.Join(repos, ", ")
+
+	l := list.New([]list.Item{
+		item{title: "Loading issues…", desc: "Fetching from " + label},
+	}, delegate.New(), 0, 0)
 	l.SetShowHelp(false)
+	l.SetShowStatusBar(false) // replaced by statusLine
+	// Quitting goes through the root model so state gets flushed
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	// ? opens the search prompt; the help it would show is hidden anyway
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
 
-	return Model{
-		list: l,
+	seen := map[string]map[int]bool{}
+	for _, repo := range repos {
+		seen[repo] = loadSeen(repo)
 	}
+	m := Model{
+		list:      l,
+		repos:     repos,
+		sort:      configuredSort(),
+		loading:   true,
+		seen:      seen,
+		poller:    &refresh.Poller{},
+		inflight:  &refresh.Inflight{},
+		searching: &refresh.Inflight{},
+	}
+	m.setTitle()
+	return m
+}
+
+// setTitle names the repos and the state, or the search as sent, and the
+// sort order above the list.
+func (m *Model) setTitle() {
+	if m.search != nil {
+		m.list.Title = fmt.Sprintf("GitHub search: %s • %s", effectiveQuery(m.search.query, m.state), m.sort)
+		return
+	}
+	m.list.Title = fmt.Sprintf("GitHub Issues (%s) • %s • %s", strings.Join(m.repos, ", "), m.state, m.sort)
+}
+
+// Repo names the monitored repositories, comma-separated.
+func (m Model) Repo() string            { return strings.Join(m.repos, ", ") }
+func (m Model) Repos() []string         { return m.repos }
+func (m Model) Issues() []GitHubIssue   { return m.issues }
+func (m Model) Loading() bool           { return m.loading }
+func (m Model) State() issuestate.State { return m.state }
+func (m Model) Err() error              { return m.err }
+
+// Filtering reports whether the user is typing a filter, so keys belong
+// to the list.
+func (m Model) Filtering() bool { return m.list.FilterState() == list.Filtering }
+
+// SelectedIssue returns the highlighted issue, if it is a real one.
+func (m Model) SelectedIssue() (GitHubIssue, bool) {
+	sel, ok := m.list.SelectedItem().(item)
+	if !ok || sel.number == 0 {
+		return GitHubIssue{}, false
+	}
+	for _, issue := range m.shown() {
+		if issue.Repo == sel.repo && issue.Number == sel.number {
+			return issue, true
+		}
+	}
+	return GitHubIssue{}, false
 }
 
 // -- Messages --
 
-type issuesFetchedMsg []GitHubIssue
-type errMsg error
+// issuesFetchedMsg carries the issues of every repo that could be fetched,
+// plus the failures of the others.
+type issuesFetchedMsg struct {
+	id       int // the fetch it answers; see refresh.Inflight
+	issues   []GitHubIssue
+	failures []repoError
+	took     time.Duration // how long fetching every repo took
+}
+type errMsg struct {
+	id  int
+	err error
+}
 
 // -- Commands --
 
-func fetchIssues() tea.Cmd {
-	return func() tea.Msg {
-		// Example: Fetch issues from Bubble Tea repo
-		// In a real app, you'd read a config for the repo
-		url := "https://api.github.com/repos/charmbracelet/bubbletea/issues?state=open&per_page=10"
+// fetchRepo fetches the issues of one repo in state.
+func fetchRepo(ctx context.Context, repo string, state issuestate.State, order issueSort) ([]GitHubIssue, error) {
+	if demo.Enabled() {
+		var issues []GitHubIssue
+		err := json.Unmarshal(demo.Fixture("github"), &issues)
+		return issues, err
+	}
 
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Add("User-Agent", "TermiFlow")
+	req := newIssuesRequest(repo, config.Secret("GITHUB_TOKEN"), state, order).WithContext(ctx)
 
-		// Optional: Add token if present
-		token := os.Getenv("GITHUB_TOKEN")
-		if token != "" {
-			req.Header.Add("Authorization", "Bearer "+token)
-		}
+	client := httpclient.New(10 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			return errMsg(err)
-		}
-		defer resp.Body.Close()
+	return decodeIssues(resp)
+}
 
-		if resp.StatusCode != 200 {
-			return errMsg(fmt.Errorf("API Error: %s", resp.Status))
-		}
+// issuesURL lists repo's issues in state; the API's states are named like
+// issuestate's.
+func issuesURL(repo string, state issuestate.State, order issueSort) string {
+	return fmt.Sprintf("https://api.github.com/repos/%s/issues?state=%s&per_page=%d", repo, state, perPage()) + order.query()
+}
 
-		var issues []GitHubIssue
-		if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
-			return errMsg(err)
-		}
+// Issues fetched per repo: GITHUB_PER_PAGE, up to the API's limit of 100.
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+)
+
+func perPage() int {
+	if n, err := strconv.Atoi(os.Getenv("GITHUB_PER_PAGE")); err == nil && n > 0 {
+		return min(n, maxPerPage)
+	}
+	return defaultPerPage
+}
+
+func newIssuesRequest(repo, token string, state issuestate.State, order issueSort) *http.Request {
+	req, _ := http.NewRequest("GET", issuesURL(repo, state, order), nil)
+	// Optional: Add token if present
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+	httpclient.SetHeaders(req, httpclient.GitHub)
+	return req
+}
 
-		return issuesFetchedMsg(issues)
+func decodeIssues(resp *http.Response) ([]GitHubIssue, error) {
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("API Error: %s", resp.Status)
 	}
+	var issues []GitHubIssue
+	err := httpclient.DecodeJSON(resp, httpclient.GitHub, &issues)
+	return issues, err
+}
+
+// issueItems maps issues to list items, flagging open ones not in seen
+// unless seen is nil. With several repos each title starts with its repo,
+// so filtering by name works.
+func issueItems(issues []GitHubIssue, seen map[string]map[int]bool, multi bool) []list.Item {
+	var items []list.Item
+	for _, issue := range issues {
+		title := fmt.Sprintf("#%d %s", issue.Number, issue.Title)
+		if multi {
+			title = issue.Repo + title
+		}
+		items = append(items, item{
+			repo:   issue.Repo,
+			number: issue.Number,
+			title:  title,
+			desc:   fmt.Sprintf("by %s [%s]", issue.User.Login, issue.State),
+			labels: issue.Labels,
+			isNew:  seen != nil && issue.State == "open" && !seen[issue.Repo][issue.Number],
+		})
+	}
+	return items
 }
 
 // -- Update --
 
 func (m Model) Init() tea.Cmd {
-	return fetchIssues()
+	return tea.Batch(m.fetch(), m.poller.Start())
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.form != nil {
+			return m.updateForm(msg)
+		}
+		if m.diff != nil {
+			return m.updateDiff(msg)
+		}
+		if m.detail != nil {
+			return m.updateDetail(msg)
+		}
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if m.search != nil && m.list.FilterState() == list.Unfiltered {
+			if cmd, ok := m.updateSearch(msg); ok {
+				return m, cmd
+			}
+		}
+		switch msg.String() {
+		case "?":
+			return m, m.openPrompt()
+		case "enter":
+			return m, m.openDetail()
+		case "d":
+			return m, m.openDiff()
+		case "n":
+			cmd, err := m.NewIssue("", "")
+			if err != nil {
+				return m, m.list.NewStatusMessage(err.Error())
+			}
+			return m, cmd
+		case "O":
+			repo := m.repos[0]
+			if sel, ok := m.list.SelectedItem().(item); ok && sel.repo != "" {
+				repo = sel.repo
+			}
+			url := fmt.Sprintf("https://github.com/%s/issues", repo)
+			if err := browser.Open(url); err != nil {
+				return m, m.list.NewStatusMessage(fmt.Sprintf("Could not open browser: %v", err))
+			}
+			return m, m.list.NewStatusMessage("Opened " + url)
+		case "r":
+			m.loading = true
+			return m, tea.Batch(m.fetch(), m.list.NewStatusMessage("Refreshing…"))
+		case "s", "S":
+			if msg.String() == "s" {
+				m.sort = m.sort.next()
+			} else {
+				m.sort = m.sort.flipped()
+			}
+			m.setTitle()
+			status := m.list.NewStatusMessage("Sorting by " + m.sort.String() + "…")
+			if m.search != nil {
+				return m, tea.Batch(m.fetchSearch(), status)
+			}
+			m.loading = true
+			return m, tea.Batch(m.fetch(), status)
+		case "x":
+			m.state = m.state.Next()
+			m.setTitle()
+			status := m.list.NewStatusMessage("Loading " + m.state.Issues() + "…")
+			if m.search != nil {
+				return m, tea.Batch(m.fetchSearch(), status)
+			}
+			m.loading = true
+			return m, tea.Batch(m.fetch(), status)
+		case "f":
+			if len(m.repos) > 1 {
+				m.only = nextRepo(m.repos, m.only)
+				m.setItems()
+				return m, nil
+			}
+		}
+
 	case tea.WindowSizeMsg:
-		m.list.SetSize(msg.Width, msg.Height)
+		m.SetSize(msg.Width, msg.Height)
+
+	case refresh.TickMsg:
+		return m, m.poller.Tick(msg, m.loading, func() tea.Cmd {
+			m.loading = true
+			return m.fetch()
+		})
+
+	case issueCreatedMsg:
+		issue := msg.issue
+		m.form = nil
+		if m.seen[issue.Repo] != nil {
+			m.seen[issue.Repo][issue.Number] = true // it's ours, don't flag it as new
+			_ = saveSeen(issue.Repo, m.seen[issue.Repo])
+		}
+		m.loading = true
+		status := fmt.Sprintf("Created %s#%d", issue.Repo, issue.Number)
+		return m, tea.Batch(m.fetch(), m.list.NewStatusMessage(status))
+
+	case diffFetchedMsg:
+		if d := m.diff; d != nil && d.repo == msg.repo && d.number == msg.number {
+			d.loading = false
+			d.err = msg.err
+			d.truncated = msg.truncated
+			d.vp.SetContent(highlightDiff(msg.diff))
+		}
+		return m, nil
+
+	case avatarFetchedMsg:
+		if d := m.detail; d != nil && d.issue.User.AvatarURL == msg.url && len(msg.data) > 0 {
+			d.avatar = termimage.Render(msg.data, msg.mimeType, avatarCols)
+			d.view.SetMeta(d.meta())
+		}
+		return m, nil
+
+	case searchResultsMsg:
+		if !m.searching.Current(msg.id) || m.search == nil {
+			return m, nil
+		}
+		m.search.loading = false
+		m.search.issues = msg.issues
+		m.search.total = msg.total
+		m.search.took = msg.took
+		m.setItems()
+		m.list.Select(0)
+		return m, nil
+
+	case searchErrMsg:
+		if !m.searching.Current(msg.id) || m.search == nil {
+			return m, nil
+		}
+		m.search.loading = false
+		m.search.err = msg.err
+		return m, nil
+
+	case createErrMsg:
+		if m.form != nil {
+			m.form.submitting = false
+			m.form.err = msg.err
+		}
+		return m, nil
 
 	case issuesFetchedMsg:
-		var items []list.Item
-		for _, issue := range msg {
-			items = append(items, item{
-				title: fmt.Sprintf("#%d %s", issue.Number, issue.Title),
-				desc:  fmt.Sprintf("by %s [%s]", issue.User.Login, issue.State),
-			})
-		}
-		m.list.SetItems(items)
+		if !m.inflight.Current(msg.id) {
+			return m, nil
+		}
+		for _, repo := range m.repos {
+			if m.seen[repo] == nil && m.state != issuestate.Closed && !failed(msg.failures, repo) {
+				// First time tracking this repo: treat what's there as seen
+				m.seen[repo] = map[int]bool{}
+				for _, issue := range msg.issues {
+					if issue.Repo == repo {
+						m.seen[repo][issue.Number] = true
+					}
+				}
+				_ = saveSeen(repo, m.seen[repo]) // best effort; retried on MarkSeen
+			}
+		}
+		m.unseen = 0
+		for _, issue := range msg.issues {
+			if issue.State == "open" && !m.seen[issue.Repo][issue.Number] {
+				m.unseen++
+			}
+		}
+		m.announceNewIssues(msg.issues)
+		m.issues = msg.issues
+		m.failures = msg.failures
+		m.took = msg.took
+		m.setItems()
 		m.loading = false
+		m.err = nil
+		cmd = m.poller.Done(nil)
 
 	case errMsg:
-		m.err = msg
+		if !m.inflight.Current(msg.id) {
+			return m, nil
+		}
 		m.loading = false
+		if m.poller.Polling() {
+			cmd = m.poller.Done(msg.err)
+			if m.issues != nil {
+				// Keep showing the last good list while offline or rate-limited
+				return m, tea.Batch(cmd, m.list.NewStatusMessage(fmt.Sprintf("Auto-refresh failed, retrying later: %v", msg.err)))
+			}
+		}
+		m.err = msg.err
 	}
 
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	var listCmd tea.Cmd
+	m.list, listCmd = m.list.Update(msg)
+	return m, tea.Batch(cmd, listCmd)
 }
 
 func (m Model) View() string {
-	if m.err != nil {
+	if m.form != nil {
+		return m.formView()
+	}
+	if m.diff != nil {
+		return m.diffView()
+	}
+	if m.detail != nil {
+		return m.detailView()
+	}
+	if m.err != nil && m.search == nil {
 		return fmt.Sprintf("Error: %v", m.err)
 	}
 	// We can add a spinner here if m.loading
-	return lipgloss.NewStyle().Margin(1, 2).Render(m.list.View())
+	// Clip to the list's width: its title bar can run a column over when
+	// cut short, and the status line isn't cut at all
+	body := lipgloss.NewStyle().MaxWidth(m.list.Width()).Render(m.list.View() + "\n" + m.statusLine())
+	return layout.Frame.Render(body)
+}
+
+// statusLine shows how many issues match the current filter plus a filter
+// hint, or the search prompt while it's open.
+func (m Model) statusLine() string {
+	if m.prompt != nil {
+		return m.prompt.View() + statusStyle.Render(" • enter search • esc cancel")
+	}
+	if m.search != nil && m.list.FilterState() == list.Unfiltered {
+		return m.searchStatus()
+	}
+	total := m.count
+	if total == 0 {
+		return m.failureNote()
+	}
+	switch m.list.FilterState() {
+	case list.Filtering:
+		return statusStyle.Render(fmt.Sprintf("%d of %d issues • enter apply • esc cancel", len(m.list.VisibleItems()), total))
+	case list.FilterApplied:
+		return statusStyle.Render(fmt.Sprintf("%d of %d issues • esc clear filter", len(m.list.VisibleItems()), total))
+	}
+	line := fmt.Sprintf("%d issues • fetched in %s • enter view • / filter • ? search • s sort • x open/closed/all • n new issue", total, m.took.Round(10*time.Millisecond))
+	if len(m.repos) > 1 {
+		shown := "all repos"
+		if m.only != "" {
+			shown = m.only
+		}
+		line += fmt.Sprintf(" • f repo (%s)", shown)
+	}
+	return statusStyle.Render(line) + m.failureNote()
 }
 
 func (m *Model) SetSize(width, height int) {
-	m.list.SetSize(width, height)
+	m.width, m.height = width, height
+	// Leave room for the margin, so long titles are cut before the edge,
+	// and for the status line
+	m.list.SetSize(max(width-layout.Margin, 0), max(height-1, 0))
+	m.sizeForm()
+	if m.prompt != nil {
+		m.prompt.Width = max(width-40, 20)
+	}
+	if m.diff != nil {
+		m.diff.vp.Width, m.diff.vp.Height = max(width-layout.Margin, 0), max(height-4, 1)
+	}
+	if m.detail != nil {
+		m.detail.view.SetSize(width, height)
+	}
 }
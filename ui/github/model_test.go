@@ -0,0 +1,194 @@
+package github
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"termiflow/ui/issuestate"
+)
+
+func TestIssuesURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		perPage string
+		state   issuestate.State
+		order   issueSort
+		want    string
+	}{
+		{"defaults", "", issuestate.Open, issueSort{"created", "desc"},
+			"https://api.github.com/repos/o/r/issues?state=open&per_page=10&sort=created&direction=desc"},
+		{"closed by comments", "25", issuestate.Closed, issueSort{"comments", "asc"},
+			"https://api.github.com/repos/o/r/issues?state=closed&per_page=25&sort=comments&direction=asc"},
+		{"all, capped page size", "500", issuestate.All, issueSort{"updated", "desc"},
+			"https://api.github.com/repos/o/r/issues?state=all&per_page=100&sort=updated&direction=desc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_PER_PAGE", tt.perPage)
+			if got := issuesURL("o/r", tt.state, tt.order); got != tt.want {
+				t.Errorf("issuesURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchURL(t *testing.T) {
+	t.Setenv("GITHUB_PER_PAGE", "")
+	tests := []struct {
+		query string
+		page  int
+		order issueSort
+		want  string
+	}{
+		{"label:bug is:open", 1, issueSort{"created", "desc"},
+			"https://api.github.com/search/issues?q=label%3Abug+is%3Aopen&per_page=10&page=1&sort=created&order=desc"},
+		{"repo:o/r crash", 3, issueSort{"comments", "asc"},
+			"https://api.github.com/search/issues?q=repo%3Ao%2Fr+crash&per_page=10&page=3&sort=comments&order=asc"},
+	}
+	for _, tt := range tests {
+		if got := searchURL(tt.query, tt.page, tt.order); got != tt.want {
+			t.Errorf("searchURL(%q, %d) = %q, want %q", tt.query, tt.page, got, tt.want)
+		}
+	}
+}
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestDecodeIssues(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		want    []int // issue numbers
+		wantErr string
+	}{
+		{"issues", response(200, `[{"number": 1, "title": "a"}, {"number": 2, "title": "b", "pull_request": {"url": "u"}}]`), []int{1, 2}, ""},
+		{"empty", response(200, `[]`), []int{}, ""},
+		{"error status", response(404, `{"message": "Not Found"}`), nil, "API Error"},
+		{"malformed", response(200, `[{"number": "one"}]`), nil, "GitHub"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := decodeIssues(tt.resp)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []int{}
+			for _, issue := range issues {
+				got = append(got, issue.Number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("numbers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueItems(t *testing.T) {
+	issue := func(repo string, number int, state string) GitHubIssue {
+		i := GitHubIssue{Number: number, Title: "Title", State: state, Repo: repo}
+		i.User.Login = "ana"
+		return i
+	}
+	issues := []GitHubIssue{issue("o/r", 1, "open"), issue("o/r", 2, "open"), issue("o/s", 3, "closed")}
+	seen := map[string]map[int]bool{"o/r": {1: true}}
+	tests := []struct {
+		name   string
+		seen   map[string]map[int]bool
+		multi  bool
+		titles []string
+		isNew  []bool
+	}{
+		{"one repo, no tracking", nil, false, []string{"#1 Title", "#2 Title", "#3 Title"}, []bool{false, false, false}},
+		{"only unseen open issues are new", seen, false, []string{"#1 Title", "#2 Title", "#3 Title"}, []bool{false, true, false}},
+		{"several repos name theirs", nil, true, []string{"o/r#1 Title", "o/r#2 Title", "o/s#3 Title"}, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var titles []string
+			var isNew []bool
+			for _, li := range issueItems(issues, tt.seen, tt.multi) {
+				it := li.(item)
+				titles = append(titles, it.title)
+				isNew = append(isNew, it.isNew)
+				if it.desc != "by ana ["+issues[len(titles)-1].State+"]" {
+					t.Errorf("desc = %q", it.desc)
+				}
+			}
+			if !reflect.DeepEqual(titles, tt.titles) || !reflect.DeepEqual(isNew, tt.isNew) {
+				t.Errorf("titles %q new %v, want %q new %v", titles, isNew, tt.titles, tt.isNew)
+			}
+		})
+	}
+	if got := issueItems(nil, nil, false); len(got) != 0 {
+		t.Errorf("issueItems(nil) = %v, want no items", got)
+	}
+}
+
+func TestSearchResults(t *testing.T) {
+	var result struct {
+		Items []searchItem `json:"items"`
+	}
+	err := json.Unmarshal([]byte(`{"items": [
+		{"number": 7, "title": "Crash", "repository_url": "https://api.github.com/repos/o/r"},
+		{"number": 9, "title": "Typo", "repository_url": "https://api.github.com/repos/other/docs"}
+	]}`), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		number int
+		repo   string
+	}{
+		{7, "o/r"},
+		{9, "other/docs"},
+	}
+	issues := searchResults(result.Items)
+	if len(issues) != len(tests) {
+		t.Fatalf("got %d issues, want %d", len(issues), len(tests))
+	}
+	for i, tt := range tests {
+		if issues[i].Number != tt.number || issues[i].Repo != tt.repo {
+			t.Errorf("issue %d = #%d in %q, want #%d in %q", i, issues[i].Number, issues[i].Repo, tt.number, tt.repo)
+		}
+	}
+}
+
+func TestEffectiveQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		state issuestate.State
+		want  string
+	}{
+		{"crash", issuestate.Open, "crash is:open"},
+		{"crash", issuestate.Closed, "crash is:closed"},
+		{"crash", issuestate.All, "crash"},
+		{"crash is:closed", issuestate.Open, "crash is:closed"},
+		{"is:open crash", issuestate.Closed, "is:open crash"},
+		{"crash IS:Closed", issuestate.Open, "crash IS:Closed"},
+		{"crash state:closed", issuestate.Open, "crash state:closed"},
+		{"crash -is:open", issuestate.Open, "crash -is:open"},
+		{"crash is:pr", issuestate.Open, "crash is:pr is:open"},
+		{"this:open", issuestate.Open, "this:open is:open"},
+	}
+	for _, tt := range tests {
+		if got := effectiveQuery(tt.query, tt.state); got != tt.want {
+			t.Errorf("effectiveQuery(%q, %v) = %q, want %q", tt.query, tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"termiflow/config"
+	"termiflow/gitrepo"
+	"termiflow/ui/issuestate"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Multiple Repositories --
+
+var warnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF8700"))
+
+// maxConcurrentFetches bounds parallel requests so a long GITHUB_REPOS list
+// doesn't trip GitHub's secondary rate limits.
+const maxConcurrentFetches = 4
+
+type repoError struct {
+	repo string
+	err  error
+}
+
+// ConfiguredRepos returns GITHUB_REPOS (comma-separated owner/name list)
+// when set, otherwise the single detected or default repo.
+func ConfiguredRepos() []string {
+	var repos []string
+	for _, r := range strings.Split(os.Getenv("GITHUB_REPOS"), ",") {
+		if r = strings.TrimSpace(r); r != "" && !slices.Contains(repos, r) {
+			repos = append(repos, r)
+		}
+	}
+	if len(repos) == 0 {
+		repos = []string{gitrepo.Default()}
+	}
+	return repos
+}
+
+// ApplyConfig picks up GitHub settings changed by /reload: the repos, sort
+// order, page size or token. Any change refetches.
+func (m *Model) ApplyConfig(changed config.Changes) tea.Cmd {
+	if !changed.Any("GITHUB_") {
+		return nil
+	}
+	m.repos = ConfiguredRepos()
+	for _, repo := range m.repos {
+		if m.seen[repo] == nil {
+			m.seen[repo] = loadSeen(repo)
+		}
+	}
+	if !slices.Contains(m.repos, m.only) {
+		m.only = ""
+	}
+	m.sort = configuredSort()
+	m.setTitle()
+	m.loading = true
+	return m.fetch()
+}
+
+// fetch starts fetching the issues, cancelling any fetch still running.
+func (m Model) fetch() tea.Cmd {
+	ctx, id := m.inflight.Start()
+	return fetchIssues(ctx, id, m.repos, m.state, m.sort)
+}
+
+// Cancel stops the fetch in flight, for quitting.
+func (m Model) Cancel() { m.inflight.Cancel() }
+
+// fetchIssues fetches every repo concurrently and merges the results in
+// the sort order. It only fails as a whole when every repo failed.
+func fetchIssues(ctx context.Context, id int, repos []string, state issuestate.State, order issueSort) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		issues, failures := fetchAll(ctx, repos, state, order)
+		if len(failures) == len(repos) {
+			if len(repos) == 1 {
+				return errMsg{id, failures[0].err}
+			}
+			return errMsg{id, joinFailures(failures)}
+		}
+		return issuesFetchedMsg{id: id, issues: issues, failures: failures, took: time.Since(start)}
+	}
+}
+
+// Fetch fetches the open issues of repos the way the tab does, in the
+// configured sort order, for headless use. Issues from the repos that could
+// be fetched are returned alongside an error naming the others.
+func Fetch(ctx context.Context, repos []string) ([]GitHubIssue, error) {
+	issues, failures := fetchAll(ctx, repos, issuestate.Open, configuredSort())
+	if len(failures) == 0 {
+		return issues, nil
+	}
+	return issues, joinFailures(failures)
+}
+
+func fetchAll(ctx context.Context, repos []string, state issuestate.State, order issueSort) ([]GitHubIssue, []repoError) {
+	results := make([][]GitHubIssue, len(repos))
+	errs := make([]error, len(repos))
+	sem := make(chan struct{}, maxConcurrentFetches)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = fetchRepo(ctx, repo, state, order)
+		}()
+	}
+	wg.Wait()
+
+	var issues []GitHubIssue
+	var failures []repoError
+	for i, repo := range repos {
+		if errs[i] != nil {
+			failures = append(failures, repoError{repo, errs[i]})
+			continue
+		}
+		for _, issue := range results[i] {
+			issue.Repo = repo
+			issues = append(issues, issue)
+		}
+	}
+	if len(repos) > 1 {
+		order.apply(issues)
+	}
+	return issues, failures
+}
+
+func joinFailures(failures []repoError) error {
+	var all []error
+	for _, f := range failures {
+		all = append(all, fmt.Errorf("%s: %w", f.repo, f.err))
+	}
+	return errors.Join(all...)
+}
+
+func failed(failures []repoError, repo string) bool {
+	for _, f := range failures {
+		if f.repo == repo {
+			return true
+		}
+	}
+	return false
+}
+
+// nextRepo cycles the repo narrowing: all, then each repo in turn.
+func nextRepo(repos []string, only string) string {
+	for i, r := range repos {
+		if r == only {
+			if i+1 < len(repos) {
+				return repos[i+1]
+			}
+			return ""
+		}
+	}
+	return repos[0]
+}
+
+// shown is the issues behind the list: the search results in search mode,
+// otherwise the fetched issues.
+func (m Model) shown() []GitHubIssue {
+	if m.search != nil {
+		return m.search.issues
+	}
+	return m.issues
+}
+
+// setItems shows the search results or the fetched issues, narrowed to
+// m.only if set. The selected issue stays selected wherever it moved.
+func (m *Model) setItems() {
+	selected, _ := m.list.SelectedItem().(item)
+	var items []list.Item
+	if m.search != nil {
+		// Search results span repos and aren't tracked as seen
+		items = issueItems(m.search.issues, nil, true)
+	} else {
+		issues := m.issues
+		if m.only != "" {
+			issues = nil
+			for _, issue := range m.issues {
+				if issue.Repo == m.only {
+					issues = append(issues, issue)
+				}
+			}
+		}
+		items = issueItems(issues, m.seen, len(m.repos) > 1)
+	}
+	m.count = len(items)
+	if len(items) > 0 {
+		m.list.SetItems(items)
+		// Select indexes visible items, so filtered lists are left alone
+		if m.list.FilterState() == list.Unfiltered && selected.number != 0 {
+			if i := slices.IndexFunc(items, func(it list.Item) bool {
+				return it.(item).repo == selected.repo && it.(item).number == selected.number
+			}); i >= 0 {
+				m.list.Select(i)
+			}
+		}
+		return
+	}
+	if m.search != nil {
+		m.list.SetItems([]list.Item{item{title: "No matching issues", desc: "Nothing matches " + m.search.query}})
+		return
+	}
+	shown := m.only
+	if shown == "" {
+		shown = m.Repo()
+	}
+	m.list.SetItems([]list.Item{item{title: "No " + m.state.Issues(), desc: shown + " has no " + m.state.Issues() + "."}})
+}
+
+// failureNote lists repos whose issues are missing because their fetch
+// failed.
+func (m Model) failureNote() string {
+	if len(m.failures) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, f := range m.failures {
+		parts = append(parts, fmt.Sprintf("%s: %v", f.repo, f.err))
+	}
+	return warnStyle.Render(" • could not load " + strings.Join(parts, "; "))
+}
@@ -0,0 +1,259 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"termiflow/browser"
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/ui/issuestate"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Issue Search (?) --
+
+// searchLimit is as deep as the search API pages: it returns at most 1000
+// results for a query.
+const searchLimit = 1000
+
+// search is a GitHub issue search shown in place of the repo listing.
+type search struct {
+	query   string
+	page    int // 1-based
+	total   int // total_count, results across every page
+	issues  []GitHubIssue
+	took    time.Duration // how long the last page took
+	loading bool
+	err     error
+}
+
+// stateQualifier matches a state the query asks for itself, e.g. is:closed
+// or -state:open, which the tab's open/closed toggle then leaves alone.
+var stateQualifier = regexp.MustCompile(`(?i)(^|\s)-?(is|state):(open|closed)\b`)
+
+// effectiveQuery is the query as sent: the user's, plus the tab's state
+// unless the query names one.
+func effectiveQuery(query string, state issuestate.State) string {
+	if state == issuestate.All || stateQualifier.MatchString(query) {
+		return query
+	}
+	return query + " is:" + state.String()
+}
+
+// pages is how many pages of results the API will serve.
+func (s *search) pages() int {
+	n := min(s.total, searchLimit)
+	return max((n+perPage()-1)/perPage(), 1)
+}
+
+type searchResultsMsg struct {
+	id     int
+	issues []GitHubIssue
+	total  int
+	took   time.Duration
+}
+type searchErrMsg struct {
+	id  int
+	err error
+}
+
+// openPrompt opens the query prompt, starting from the current query.
+func (m *Model) openPrompt() tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = "Search GitHub: "
+	ti.Placeholder = "is:open label:bug repo:owner/name"
+	ti.CharLimit = 256
+	ti.Width = max(m.width-40, 20)
+	if m.search != nil {
+		ti.SetValue(m.search.query)
+	}
+	m.prompt = &ti
+	return ti.Focus()
+}
+
+// updatePrompt handles keys while the query prompt is open: enter runs the
+// search from its first page and esc closes the prompt.
+func (m Model) updatePrompt(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt = nil
+		return m, nil
+	case "enter":
+		query := strings.TrimSpace(m.prompt.Value())
+		m.prompt = nil
+		if query == "" {
+			return m, nil
+		}
+		m.search = &search{query: query, page: 1}
+		m.setTitle()
+		return m, m.fetchSearch()
+	}
+	var cmd tea.Cmd
+	*m.prompt, cmd = m.prompt.Update(msg)
+	return m, cmd
+}
+
+// updateSearch handles the keys only search mode has: ] and [ page through
+// the results and esc goes back to the repo listing. It reports whether it
+// used the key.
+func (m *Model) updateSearch(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s := m.search
+	switch msg.String() {
+	case "]":
+		if s.loading || s.page >= s.pages() {
+			return nil, true
+		}
+		s.page++
+		return m.fetchSearch(), true
+	case "[":
+		if s.loading || s.page <= 1 {
+			return nil, true
+		}
+		s.page--
+		return m.fetchSearch(), true
+	case "esc":
+		m.searching.Cancel()
+		m.search = nil
+		m.setTitle()
+		m.setItems()
+		m.list.Select(0)
+		return nil, true
+	case "r":
+		return tea.Batch(m.fetchSearch(), m.list.NewStatusMessage("Searching…")), true
+	case "O":
+		u := "https://github.com/search?type=issues&q=" + url.QueryEscape(effectiveQuery(s.query, m.state))
+		if err := browser.Open(u); err != nil {
+			return m.list.NewStatusMessage(fmt.Sprintf("Could not open browser: %v", err)), true
+		}
+		return m.list.NewStatusMessage("Opened " + u), true
+	case "f":
+		return nil, true // results span repos; there's nothing to narrow
+	}
+	return nil, false
+}
+
+// fetchSearch fetches the current page of the search, cancelling any page
+// still loading. Searches have their own Inflight so an auto-refresh of the
+// repo listing doesn't cancel them.
+func (m *Model) fetchSearch() tea.Cmd {
+	m.search.loading = true
+	m.search.err = nil
+	ctx, id := m.searching.Start()
+	query, page, order, repo := effectiveQuery(m.search.query, m.state), m.search.page, m.sort, m.repos[0]
+	return func() tea.Msg {
+		start := time.Now()
+		issues, total, err := searchIssues(ctx, query, page, order, repo)
+		if err != nil {
+			return searchErrMsg{id, err}
+		}
+		return searchResultsMsg{id: id, issues: issues, total: total, took: time.Since(start)}
+	}
+}
+
+// searchItem is an entry of the search response, which names its repo
+// only by API URL.
+type searchItem struct {
+	GitHubIssue
+	RepositoryURL string `json:"repository_url"`
+}
+
+// searchIssues runs one page of a search. In demo mode it matches the
+// fixture's titles against the query and reports them as issues of repo.
+func searchIssues(ctx context.Context, query string, page int, order issueSort, repo string) ([]GitHubIssue, int, error) {
+	if demo.Enabled() {
+		var all, issues []GitHubIssue
+		if err := json.Unmarshal(demo.Fixture("github"), &all); err != nil {
+			return nil, 0, err
+		}
+		for _, issue := range all {
+			if strings.Contains(strings.ToLower(issue.Title), strings.ToLower(query)) {
+				issue.Repo = repo
+				issues = append(issues, issue)
+			}
+		}
+		return issues, len(issues), nil
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", searchURL(query, page, order), nil)
+	if token := config.Secret("GITHUB_TOKEN"); token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+	httpclient.SetHeaders(req, httpclient.GitHub)
+
+	resp, err := httpclient.New(10 * time.Second).Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if wait, limited := httpclient.RetryAfter(resp); limited {
+		return nil, 0, searchLimitError(wait)
+	}
+	if resp.StatusCode != 200 {
+		return nil, 0, fmt.Errorf("API Error: %s", resp.Status)
+	}
+
+	var result struct {
+		TotalCount int          `json:"total_count"`
+		Items      []searchItem `json:"items"`
+	}
+	if err := httpclient.DecodeJSON(resp, httpclient.GitHub, &result); err != nil {
+		return nil, 0, err
+	}
+	return searchResults(result.Items), result.TotalCount, nil
+}
+
+// searchResults takes each result's repo from its repository_url.
+func searchResults(items []searchItem) []GitHubIssue {
+	issues := make([]GitHubIssue, len(items))
+	for i, it := range items {
+		issues[i] = it.GitHubIssue
+		issues[i].Repo = strings.TrimPrefix(it.RepositoryURL, "https://api.github.com/repos/")
+	}
+	return issues
+}
+
+// searchURL asks for one page of query in the tab's sort order. The search
+// API calls the direction "order".
+func searchURL(query string, page int, order issueSort) string {
+	return fmt.Sprintf("https://api.github.com/search/issues?q=%s&per_page=%d&page=%d&sort=%s&order=%s",
+		url.QueryEscape(query), perPage(), page, order.by, order.direction)
+}
+
+// searchLimitError explains the search API's own rate limit, which is far
+// lower than the one for listing issues.
+func searchLimitError(wait time.Duration) error {
+	perMinute := "10 searches a minute without GITHUB_TOKEN"
+	if config.Secret("GITHUB_TOKEN") != "" {
+		perMinute = "30 searches a minute"
+	}
+	return fmt.Errorf("GitHub search is rate limited (%s); retry in %s", perMinute, max(wait, time.Second).Round(time.Second))
+}
+
+// searchStatus is the status line in search mode.
+func (m Model) searchStatus() string {
+	s := m.search
+	if s.err != nil {
+		return warnStyle.Render(fmt.Sprintf("Search failed: %v", s.err)) + statusStyle.Render(" • r retry • esc back to repos")
+	}
+	if s.loading && s.issues == nil {
+		return statusStyle.Render("Searching… • esc back to repos")
+	}
+	line := fmt.Sprintf("page %d of %d (%d results) • fetched in %s", s.page, s.pages(), s.total, s.took.Round(10*time.Millisecond))
+	if s.total > searchLimit {
+		line += fmt.Sprintf(", first %d shown", searchLimit)
+	}
+	if s.pages() > 1 {
+		line += " • ] next page • [ previous"
+	}
+	return statusStyle.Render(line + " • ? new search • esc back to repos")
+}
@@ -0,0 +1,110 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+
+	"termiflow/hooks"
+	"termiflow/notify"
+	"termiflow/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- New Issue Tracking --
+
+// seenFile maps "owner/name" to the issue numbers the user has already seen.
+const seenFile = "github_seen.json"
+
+// loadSeen returns the seen set for repo, or nil if the repo has never been
+// tracked (so the first fetch doesn't flag everything as new).
+func loadSeen(repo string) map[int]bool {
+	var all map[string][]int
+	if err := storage.LoadJSON(seenFile, &all); err != nil {
+		return nil
+	}
+	numbers, ok := all[repo]
+	if !ok {
+		return nil
+	}
+	seen := make(map[int]bool, len(numbers))
+	for _, n := range numbers {
+		seen[n] = true
+	}
+	return seen
+}
+
+func saveSeen(repo string, seen map[int]bool) error {
+	var all map[string][]int
+	if err := storage.LoadJSON(seenFile, &all); err != nil || all == nil {
+		all = map[string][]int{}
+	}
+	numbers := make([]int, 0, len(seen))
+	for n := range seen {
+		numbers = append(numbers, n)
+	}
+	all[repo] = numbers
+	return storage.SaveJSON(seenFile, all)
+}
+
+// NewCount is how many issues appeared since the user last viewed the tab.
+func (m Model) NewCount() int { return m.unseen }
+
+// Focus is called when the GitHub tab is shown and Blur when another tab
+// is. Looking at the tab counts as viewing its new issues.
+func (m *Model) Focus() tea.Cmd { m.focused = true; return m.MarkSeen() }
+func (m *Model) Blur()          { m.focused = false }
+func (m Model) Focused() bool   { return m.focused }
+
+// MarkSeen records every current issue as seen, clearing the tab badge.
+// The "new" markers in the list stay until the next refresh so the user
+// can still spot them. Search results don't count as viewing them.
+func (m *Model) MarkSeen() tea.Cmd {
+	if m.unseen == 0 || m.search != nil {
+		return nil
+	}
+	m.unseen = 0
+	for _, issue := range m.issues {
+		if m.seen[issue.Repo] == nil {
+			m.seen[issue.Repo] = map[int]bool{}
+		}
+		m.seen[issue.Repo][issue.Number] = true
+	}
+	var errs []error
+	for _, repo := range m.repos {
+		if m.seen[repo] != nil {
+			errs = append(errs, saveSeen(repo, m.seen[repo]))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return m.list.NewStatusMessage(fmt.Sprintf("Could not save seen issues: %v", err))
+	}
+	return nil
+}
+
+// announceNewIssues runs the on_new_github_issue hook for each unseen open
+// issue that wasn't in the previous fetch, so each shows up once per run. Ones
+// found by an auto-refresh also get a desktop notification.
+func (m Model) announceNewIssues(issues []GitHubIssue) {
+	known := map[string]bool{}
+	for _, issue := range m.issues {
+		known[fmt.Sprintf("%s#%d", issue.Repo, issue.Number)] = true
+	}
+	for _, issue := range issues {
+		ref := fmt.Sprintf("%s#%d", issue.Repo, issue.Number)
+		if issue.State != "open" || m.seen[issue.Repo][issue.Number] || known[ref] {
+			continue
+		}
+		hooks.Fire(hooks.NewGitHubIssue, map[string]any{
+			"repo":         issue.Repo,
+			"number":       issue.Number,
+			"title":        issue.Title,
+			"author":       issue.User.Login,
+			"pull_request": issue.PullRequest != nil,
+			"url":          fmt.Sprintf("https://github.com/%s/issues/%d", issue.Repo, issue.Number),
+		})
+		if m.poller.Polling() {
+			notify.Send("New GitHub issue in "+issue.Repo, fmt.Sprintf("#%d %s", issue.Number, issue.Title))
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+)
+
+// -- Sort Order --
+
+// sortFields are the orders GitHub's issues API supports, cycled with "s".
+var sortFields = []string{"created", "updated", "comments"}
+
+// issueSort is how issues are ordered: by one of sortFields, "desc" or
+// "asc".
+type issueSort struct {
+	by        string
+	direction string
+}
+
+// configuredSort reads GITHUB_SORT and GITHUB_DIRECTION, defaulting to
+// GitHub's own newest-first order.
+func configuredSort() issueSort {
+	s := issueSort{by: "created", direction: "desc"}
+	if by := os.Getenv("GITHUB_SORT"); slices.Contains(sortFields, by) {
+		s.by = by
+	}
+	if os.Getenv("GITHUB_DIRECTION") == "asc" {
+		s.direction = "asc"
+	}
+	return s
+}
+
+func (s issueSort) next() issueSort {
+	i := slices.Index(sortFields, s.by)
+	s.by = sortFields[(i+1)%len(sortFields)]
+	return s
+}
+
+func (s issueSort) flipped() issueSort {
+	if s.direction == "asc" {
+		s.direction = "desc"
+	} else {
+		s.direction = "asc"
+	}
+	return s
+}
+
+func (s issueSort) String() string {
+	arrow := "↓"
+	if s.direction == "asc" {
+		arrow = "↑"
+	}
+	return s.by + " " + arrow
+}
+
+func (s issueSort) query() string {
+	return fmt.Sprintf("&sort=%s&direction=%s", s.by, s.direction)
+}
+
+// apply orders issues merged from several repos the way the API ordered
+// each repo's own. Ties keep repo order.
+func (s issueSort) apply(issues []GitHubIssue) {
+	key := func(i GitHubIssue) int64 {
+		switch s.by {
+		case "updated":
+			return i.UpdatedAt.UnixNano()
+		case "comments":
+			return int64(i.Comments)
+		}
+		return i.CreatedAt.UnixNano()
+	}
+	sort.SliceStable(issues, func(a, b int) bool {
+		if s.direction == "asc" {
+			return key(issues[a]) < key(issues[b])
+		}
+		return key(issues[a]) > key(issues[b])
+	})
+}
@@ -0,0 +1,35 @@
+// Package issuestate is the open/closed/all filter shared by the Jira and
+// GitHub lists.
+package issuestate
+
+// State is which issues a list shows. The zero value is Open, the default
+// for both lists.
+type State int
+
+const (
+	Open State = iota
+	Closed
+	All
+)
+
+// Next cycles open → closed → all → open.
+func (s State) Next() State { return (s + 1) % 3 }
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case All:
+		return "all"
+	}
+	return "open"
+}
+
+// Issues names the issues shown, for empty-list messages: "open issues",
+// "closed issues" or just "issues".
+func (s State) Issues() string {
+	if s == All {
+		return "issues"
+	}
+	return s.String() + " issues"
+}
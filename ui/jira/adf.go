@@ -0,0 +1,262 @@
+package jira
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -- Issue Descriptions --
+
+// DescriptionText returns the issue description as plain text. API v3
+// returns Atlassian Document Format; older instances return a string.
+func (i JiraIssue) DescriptionText() string {
+	switch d := i.Fields.Description.(type) {
+	case string:
+		return d
+	case map[string]any:
+		var sb strings.Builder
+		adfText(&sb, d)
+		return strings.TrimSpace(sb.String())
+	}
+	return ""
+}
+
+// adfText appends the text of an ADF node, breaking lines after blocks.
+func adfText(sb *strings.Builder, node map[string]any) {
+	switch node["type"] {
+	case "text":
+		s, _ := node["text"].(string)
+		sb.WriteString(s)
+	case "hardBreak":
+		sb.WriteString("\n")
+	case "listItem":
+		sb.WriteString("- ")
+	}
+	content, _ := node["content"].([]any)
+	for _, c := range content {
+		if child, ok := c.(map[string]any); ok {
+			adfText(sb, child)
+		}
+	}
+	switch node["type"] {
+	case "paragraph", "heading", "codeBlock", "blockquote":
+		sb.WriteString("\n")
+	}
+}
+
+// DescriptionMarkdown returns the description as Markdown for rendering:
+// ADF is converted, and a plain string (wiki markup on older instances) is
+// passed through.
+func (i JiraIssue) DescriptionMarkdown() string {
+	switch d := i.Fields.Description.(type) {
+	case string:
+		return d
+	case map[string]any:
+		var sb strings.Builder
+		adfBlocks(&sb, adfContent(d), "")
+		return strings.TrimSpace(sb.String())
+	}
+	return ""
+}
+
+func adfContent(node map[string]any) []map[string]any {
+	raw, _ := node["content"].([]any)
+	var children []map[string]any
+	for _, c := range raw {
+		if child, ok := c.(map[string]any); ok {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+func adfAttr(node map[string]any, name string) string {
+	attrs, _ := node["attrs"].(map[string]any)
+	switch v := attrs[name].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// adfBlocks writes block nodes as Markdown, each line starting with indent
+// (list nesting or a blockquote marker).
+func adfBlocks(sb *strings.Builder, nodes []map[string]any, indent string) {
+	for _, node := range nodes {
+		switch node["type"] {
+		case "paragraph":
+			writeIndented(sb, adfInline(adfContent(node)), indent)
+		case "heading":
+			level, _ := strconv.Atoi(adfAttr(node, "level"))
+			writeIndented(sb, strings.Repeat("#", min(max(level, 1), 6))+" "+adfInline(adfContent(node)), indent)
+		case "codeBlock":
+			var code strings.Builder
+			for _, t := range adfContent(node) {
+				s, _ := t["text"].(string)
+				code.WriteString(s)
+			}
+			writeIndented(sb, "```"+adfAttr(node, "language")+"\n"+strings.TrimRight(code.String(), "\n")+"\n```", indent)
+		case "bulletList", "orderedList":
+			adfList(sb, node, indent)
+			sb.WriteString("\n")
+		case "blockquote", "panel":
+			adfBlocks(sb, adfContent(node), indent+"> ")
+		case "rule":
+			writeIndented(sb, "---", indent)
+		case "table":
+			adfTable(sb, node, indent)
+		case "mediaSingle", "mediaGroup", "media":
+			writeIndented(sb, "_[attachment]_", indent)
+		default:
+			// Unknown blocks: keep whatever text they hold
+			if children := adfContent(node); len(children) > 0 {
+				adfBlocks(sb, children, indent)
+			} else if text := adfInline([]map[string]any{node}); text != "" {
+				writeIndented(sb, text, indent)
+			}
+		}
+	}
+}
+
+// writeIndented writes a block followed by a blank line, prefixing each
+// line with indent.
+func writeIndented(sb *strings.Builder, block, indent string) {
+	for _, line := range strings.Split(block, "\n") {
+		sb.WriteString(strings.TrimRight(indent+line, " ") + "\n")
+	}
+	sb.WriteString(strings.TrimRight(indent, " ") + "\n")
+}
+
+// adfList writes a bullet or ordered list; nested lists are indented under
+// their item.
+func adfList(sb *strings.Builder, list map[string]any, indent string) {
+	n, _ := strconv.Atoi(adfAttr(list, "order"))
+	n = max(n, 1)
+	for _, item := range adfContent(list) {
+		marker := "- "
+		if list["type"] == "orderedList" {
+			marker = fmt.Sprintf("%d. ", n)
+			n++
+		}
+		first := true
+		for _, child := range adfContent(item) {
+			switch child["type"] {
+			case "bulletList", "orderedList":
+				adfList(sb, child, indent+strings.Repeat(" ", len(marker)))
+			default:
+				var block strings.Builder
+				adfBlocks(&block, []map[string]any{child}, "")
+				text := strings.TrimSpace(block.String())
+				prefix := indent + marker
+				if !first {
+					prefix = indent + strings.Repeat(" ", len(marker))
+				}
+				for j, line := range strings.Split(text, "\n") {
+					if j > 0 {
+						prefix = indent + strings.Repeat(" ", len(marker))
+					}
+					sb.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+				}
+				first = false
+			}
+		}
+	}
+}
+
+// adfTable writes a table as a Markdown pipe table, the first row as the
+// header.
+func adfTable(sb *strings.Builder, table map[string]any, indent string) {
+	var lines []string
+	for i, row := range adfContent(table) {
+		var cells []string
+		for _, cell := range adfContent(row) {
+			var text []string
+			for _, p := range adfContent(cell) {
+				text = append(text, adfInline(adfContent(p)))
+			}
+			cells = append(cells, strings.ReplaceAll(strings.Join(text, " "), "|", `\|`))
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	if len(lines) > 0 {
+		writeIndented(sb, strings.Join(lines, "\n"), indent)
+	}
+}
+
+// adfInline renders inline nodes: text with its marks, line breaks,
+// mentions, emoji, dates and smart links.
+func adfInline(nodes []map[string]any) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		switch node["type"] {
+		case "text":
+			s, _ := node["text"].(string)
+			sb.WriteString(adfMarks(s, node))
+		case "hardBreak":
+			// A backslash, not two spaces: writeIndented trims trailing
+			// spaces, which would turn the break into a soft one
+			sb.WriteString("\\\n")
+		case "mention":
+			name := adfAttr(node, "text")
+			if !strings.HasPrefix(name, "@") {
+				name = "@" + name
+			}
+			sb.WriteString(name)
+		case "emoji":
+			if text := adfAttr(node, "text"); text != "" {
+				sb.WriteString(text)
+			} else {
+				sb.WriteString(adfAttr(node, "shortName"))
+			}
+		case "inlineCard", "blockCard", "embedCard":
+			u := adfAttr(node, "url")
+			sb.WriteString("<" + u + ">")
+		case "date":
+			if ms, err := strconv.ParseInt(adfAttr(node, "timestamp"), 10, 64); err == nil {
+				sb.WriteString(time.UnixMilli(ms).UTC().Format("2006-01-02"))
+			}
+		case "status":
+			sb.WriteString("`" + adfAttr(node, "text") + "`")
+		default:
+			if children := adfContent(node); len(children) > 0 {
+				sb.WriteString(adfInline(children))
+			} else if text, ok := node["text"].(string); ok {
+				sb.WriteString(text)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// adfMarks wraps text in the Markdown for its marks (bold, italic, code,
+// strikethrough, links).
+func adfMarks(text string, node map[string]any) string {
+	marks, _ := node["marks"].([]any)
+	link := ""
+	for _, raw := range marks {
+		mark, _ := raw.(map[string]any)
+		switch mark["type"] {
+		case "strong":
+			text = "**" + text + "**"
+		case "em":
+			text = "_" + text + "_"
+		case "code":
+			text = "`" + text + "`"
+		case "strike":
+			text = "~~" + text + "~~"
+		case "link":
+			link = adfAttr(mark, "href")
+		}
+	}
+	if link != "" {
+		text = "[" + text + "](" + link + ")"
+	}
+	return text
+}
@@ -0,0 +1,226 @@
+package jira
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// adfDoc decodes ADF written as JSON, as the API returns it.
+func adfDoc(t *testing.T, doc string) map[string]any {
+	t.Helper()
+	var node map[string]any
+	if err := json.Unmarshal([]byte(doc), &node); err != nil {
+		t.Fatalf("bad test ADF: %v", err)
+	}
+	return node
+}
+
+func issueWith(description any) JiraIssue {
+	var i JiraIssue
+	i.Fields.Description = description
+	return i
+}
+
+func TestDescriptionMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"paragraphs",
+			`{"type":"doc","content":[
+				{"type":"paragraph","content":[{"type":"text","text":"First."}]},
+				{"type":"paragraph","content":[{"type":"text","text":"Second,"},{"type":"hardBreak"},{"type":"text","text":"broken."}]}]}`,
+			"First.\n\nSecond,\\\nbroken."},
+		{"heading levels are clamped",
+			`{"type":"doc","content":[
+				{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Steps"}]},
+				{"type":"heading","attrs":{"level":9},"content":[{"type":"text","text":"Deep"}]}]}`,
+			"## Steps\n\n###### Deep"},
+		{"code block keeps its language",
+			`{"type":"doc","content":[{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"fmt.Println(1)\n"}]}]}`,
+			"```go\nfmt.Println(1)\n```"},
+		{"blockquote and rule",
+			`{"type":"doc","content":[
+				{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"quoted"}]}]},
+				{"type":"rule"}]}`,
+			"> quoted\n>\n---"},
+		{"attachment",
+			`{"type":"doc","content":[{"type":"mediaSingle","content":[{"type":"media","attrs":{"id":"1"}}]}]}`,
+			"_[attachment]_"},
+		{"unknown block keeps its text",
+			`{"type":"doc","content":[{"type":"expand","content":[{"type":"paragraph","content":[{"type":"text","text":"hidden"}]}]}]}`,
+			"hidden"},
+		{"unknown leaf keeps its text",
+			`{"type":"doc","content":[{"type":"mystery","text":"leaf"}]}`,
+			"leaf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issueWith(adfDoc(t, tt.doc)).DescriptionMarkdown(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescriptionMarkdownPlain(t *testing.T) {
+	tests := []struct {
+		name        string
+		description any
+		want        string
+	}{
+		{"wiki markup string", "h1. Title", "h1. Title"},
+		{"none", nil, ""},
+		{"unexpected type", 42.0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issueWith(tt.description).DescriptionMarkdown(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFList(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want string
+	}{
+		{"bullets",
+			`{"type":"bulletList","content":[
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"one"}]}]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"two"}]}]}]}`,
+			"- one\n- two\n"},
+		{"ordered from a start",
+			`{"type":"orderedList","attrs":{"order":3},"content":[
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"c"}]}]},
+				{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"d"}]}]}]}`,
+			"3. c\n4. d\n"},
+		{"nested under its item",
+			`{"type":"bulletList","content":[
+				{"type":"listItem","content":[
+					{"type":"paragraph","content":[{"type":"text","text":"parent"}]},
+					{"type":"orderedList","content":[
+						{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"child"}]}]}]}]}]}`,
+			"- parent\n  1. child\n"},
+		{"second paragraph lines up with the text",
+			`{"type":"bulletList","content":[
+				{"type":"listItem","content":[
+					{"type":"paragraph","content":[{"type":"text","text":"first"}]},
+					{"type":"paragraph","content":[{"type":"text","text":"more"}]}]}]}`,
+			"- first\n  more\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			adfList(&sb, adfDoc(t, tt.list), "")
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		indent string
+		want   string
+	}{
+		{"header row and pipes escaped",
+			`{"type":"table","content":[
+				{"type":"tableRow","content":[
+					{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Key"}]}]},
+					{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"Value"}]}]}]},
+				{"type":"tableRow","content":[
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"a|b"}]}]},
+					{"type":"tableCell","content":[{"type":"paragraph","content":[{"type":"text","text":"x"}]},{"type":"paragraph","content":[{"type":"text","text":"y"}]}]}]}]}`,
+			"",
+			"| Key | Value |\n| --- | --- |\n| a\\|b | x y |\n\n"},
+		{"inside a quote",
+			`{"type":"table","content":[{"type":"tableRow","content":[{"type":"tableHeader","content":[{"type":"paragraph","content":[{"type":"text","text":"H"}]}]}]}]}`,
+			"> ",
+			"> | H |\n> | --- |\n>\n"},
+		{"empty", `{"type":"table"}`, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			adfTable(&sb, adfDoc(t, tt.table), tt.indent)
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFInline(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes string
+		want  string
+	}{
+		{"mention gets an @", `[{"type":"mention","attrs":{"text":"Ada"}},{"type":"text","text":" and "},{"type":"mention","attrs":{"text":"@Bob"}}]`, "@Ada and @Bob"},
+		{"emoji text or short name", `[{"type":"emoji","attrs":{"text":"🎉"}},{"type":"emoji","attrs":{"shortName":":shipit:"}}]`, "🎉:shipit:"},
+		{"smart link", `[{"type":"inlineCard","attrs":{"url":"https://example.com/x"}}]`, "<https://example.com/x>"},
+		{"date from milliseconds", `[{"type":"date","attrs":{"timestamp":"1700000000000"}}]`, "2023-11-14"},
+		{"status lozenge", `[{"type":"status","attrs":{"text":"DONE"}}]`, "`DONE`"},
+		{"unknown inline keeps its text", `[{"type":"placeholder","text":"fill me"}]`, "fill me"},
+		{"unknown inline with children", `[{"type":"wrapper","content":[{"type":"text","text":"inner"}]}]`, "inner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw []any
+			if err := json.Unmarshal([]byte(tt.nodes), &raw); err != nil {
+				t.Fatal(err)
+			}
+			got := adfInline(adfContent(map[string]any{"content": raw}))
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFMarks(t *testing.T) {
+	tests := []struct {
+		name string
+		node string
+		want string
+	}{
+		{"none", `{"type":"text","text":"plain"}`, "plain"},
+		{"bold", `{"type":"text","text":"b","marks":[{"type":"strong"}]}`, "**b**"},
+		{"italic", `{"type":"text","text":"i","marks":[{"type":"em"}]}`, "_i_"},
+		{"code", `{"type":"text","text":"c","marks":[{"type":"code"}]}`, "`c`"},
+		{"strike", `{"type":"text","text":"s","marks":[{"type":"strike"}]}`, "~~s~~"},
+		{"stacked", `{"type":"text","text":"x","marks":[{"type":"strong"},{"type":"em"}]}`, "_**x**_"},
+		{"link wraps the marks", `{"type":"text","text":"docs","marks":[{"type":"strong"},{"type":"link","attrs":{"href":"https://example.com"}}]}`, "[**docs**](https://example.com)"},
+		{"unknown mark ignored", `{"type":"text","text":"u","marks":[{"type":"underline"}]}`, "u"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := adfDoc(t, tt.node)
+			text, _ := node["text"].(string)
+			if got := adfMarks(text, node); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestADFBlocksIndent(t *testing.T) {
+	doc := `{"type":"doc","content":[
+		{"type":"paragraph","content":[{"type":"text","text":"a"}]},
+		{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"b"}]}]}]}]}`
+	var sb strings.Builder
+	adfBlocks(&sb, adfContent(adfDoc(t, doc)), "> ")
+	want := "> a\n>\n> - b\n\n"
+	if got := sb.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
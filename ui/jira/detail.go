@@ -0,0 +1,84 @@
+package jira
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"termiflow/browser"
+	"termiflow/ui/detail"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Issue Detail (enter) --
+
+type detailView struct {
+	issue JiraIssue
+	view  detail.Model
+	note  string // result of the last o, shown in the footer
+}
+
+// openDetail shows the selected issue with its description converted from
+// ADF and rendered as Markdown.
+func (m *Model) openDetail() tea.Cmd {
+	issue, ok := m.SelectedIssue()
+	if !ok {
+		return nil
+	}
+	title := issue.Key + " " + issue.Fields.Summary
+	meta := statusStyle.Render("Status: " + issue.Fields.Status.Name)
+	m.detail = &detailView{issue: issue, view: detail.New(title, meta, issue.DescriptionMarkdown(), m.width, m.height)}
+	return nil
+}
+
+// updateDetail handles keys while an issue is open: the viewport scrolls,
+// o opens it in the browser and esc goes back to the list.
+func (m Model) updateDetail(msg tea.KeyMsg) (Model, tea.Cmd) {
+	d := m.detail
+	switch msg.String() {
+	case "esc", "q":
+		m.detail = nil
+		return m, nil
+	case "o":
+		base := os.Getenv("JIRA_URL")
+		if base == "" {
+			d.note = "JIRA_URL is not set"
+			return m, nil
+		}
+		url := strings.TrimRight(base, "/") + "/browse/" + d.issue.Key
+		d.note = "Opened " + url
+		if err := browser.Open(url); err != nil {
+			d.note = fmt.Sprintf("Could not open browser: %v", err)
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	d.view, cmd = d.view.Update(msg)
+	return m, cmd
+}
+
+func (m Model) detailView() string {
+	d := m.detail
+	note := ""
+	if d.note != "" {
+		note = statusStyle.Render(d.note)
+	}
+	return d.view.View("o open in browser", note)
+}
+
+// Viewing reports whether an issue is open, so keys belong to it.
+func (m Model) Viewing() bool { return m.detail != nil }
+
+// ShowIssue selects the issue with key and opens it, clearing any filter
+// first. Chat references land here.
+func (m *Model) ShowIssue(key string) tea.Cmd {
+	m.list.ResetFilter()
+	for i, it := range m.list.Items() {
+		if it, ok := it.(item); ok && it.key == key {
+			m.list.Select(i)
+			return m.openDetail()
+		}
+	}
+	return m.list.NewStatusMessage(key + " is not in this list; press a to switch scope or o in chat to open it")
+}
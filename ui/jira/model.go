@@ -1,151 +1,646 @@
 package jira
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"termiflow/browser"
+	"termiflow/config"
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/jiraapi"
+	"termiflow/refresh"
+	"termiflow/ui/delegate"
+	"termiflow/ui/issuestate"
+	"termiflow/ui/layout"
+
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+var statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+// categoryStyles color a status by its statusCategory key, so progress shows
+// at a glance whatever the workflow calls its statuses.
+var categoryStyles = map[string]lipgloss.Style{
+	"new":           lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F")),
+	"indeterminate": lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")),
+	"done":          lipgloss.NewStyle().Foreground(lipgloss.Color("#5FD75F")),
+}
+
 // -- Data Structures --
 
 type JiraIssue struct {
-	Key    string `json:"key"`
-	Fields struct {
-		Summary string `json:"summary"`
-		Status  struct {
-			Name string `json:"name"`
-		} `json:"status"`
-	} `json:"fields"`
+	Key    string     `json:"key"`
+	Fields JiraFields `json:"fields"`
+}
+
+type JiraFields struct {
+	Summary string `json:"summary"`
+	Status  struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"` // new, indeterminate or done
+		} `json:"statusCategory"`
+	} `json:"status"`
+	Description any `json:"description"` // ADF document (API v3) or string
+	// All returned fields by id, used to render JIRA_EXTRA_FIELDS
+	Extra map[string]any `json:"-"`
+}
+
+func (f *JiraFields) UnmarshalJSON(data []byte) error {
+	type plain JiraFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &f.Extra)
 }
 
 type JiraSearchResponse struct {
-	Issues []JiraIssue `json:"issues"`
+	Issues []JiraIssue       `json:"issues"`
+	Names  map[string]string `json:"names"` // field id -> display name, with expand=names
+
+	// Legacy /search pagination
+	StartAt int `json:"startAt"`
+	Total   int `json:"total"`
+	// Enhanced /search/jql pagination
+	NextPageToken string `json:"nextPageToken"`
+	IsLast        bool   `json:"isLast"`
 }
 
+const statusLabel = "Status: "
+
 type item struct {
-	title string
-	desc  string
+	title    string
+	desc     string // follows the status, if any
+	key      string // issue key; empty for placeholder items
+	status   string
+	category string // statusCategory key, for coloring the status
+	pinned   bool
+}
+
+func (i item) Title() string {
+	if i.pinned {
+		return "📌 " + i.title
+	}
+	return i.title
+}
+func (i item) Description() string {
+	if i.status == "" {
+		return i.desc
+	}
+	return statusLabel + i.status + i.desc
 }
 
-func (i item) Title() string       { return i.title }
-func (i item) Description() string { return i.desc }
+// Accent colors the status by its category; the delegate draws it.
+func (i item) Accent() (from, to int, style lipgloss.Style) {
+	style, ok := categoryStyles[i.category]
+	if i.status == "" || !ok {
+		return 0, 0, style
+	}
+	return len(statusLabel), len(statusLabel) + len(i.status), style
+}
 func (i item) FilterValue() string { return i.title }
 
 // -- Model --
 
 type Model struct {
 	list    list.Model
+	issues  []JiraIssue
 	loading bool
 	err     error
+	count   int  // fetched issues, excluding placeholder items
+	all     bool // showing everyone's issues rather than just mine
+	state   issuestate.State
+	took    time.Duration // how long the last search took
+
+	names       map[string]string // field id -> display name from the last search
+	pinned      []string          // pinned issue keys, shown first
+	pinnedExtra []JiraIssue       // pinned issues outside the current search
+
+	poller   *refresh.Poller   // auto-refresh, shared by copies
+	inflight *refresh.Inflight // the latest issue fetch, likewise
+
+	detail        *detailView // issue description, nil when closed
+	width, height int
 }
 
 func New() Model {
 	l := list.New([]list.Item{
-		item{title: "Setup Required", desc: "Please set JIRA_URL, JIRA_EMAIL, JIRA_TOKEN"},
-	}, list.NewDefaultDelegate(), 0, 0)
-	l.Title = "Jira Issues"
+		item{title: "Setup Required", desc: "Please set JIRA_URL, JIRA_EMAIL, JIRA_TOKEN (or JIRA_AUTH=bearer with a PAT)"},
+	}, delegate.New(), 0, 0)
+	l.Title = listTitle(false, issuestate.Open)
 	l.SetShowHelp(false)
+	l.SetShowStatusBar(false) // replaced by statusLine
+	// Quitting goes through the root model so state gets flushed
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
 
 	return Model{
-		list: l,
+		list:     l,
+		loading:  demo.Enabled() || jiraapi.Configured(),
+		pinned:   loadPinned(),
+		poller:   &refresh.Poller{},
+		inflight: &refresh.Inflight{},
+	}
+}
+
+// listTitle names the current scope and state and flags disabled TLS
+// verification.
+func listTitle(all bool, state issuestate.State) string {
+	title := fmt.Sprintf("Jira Issues (%s) • %s", scopeName(all), state)
+	if httpclient.TLSFromEnv("JIRA").Insecure {
+		title += " ⚠ TLS VERIFICATION DISABLED"
 	}
+	return title
 }
 
+// scopeName is how titles name the scope.
+func scopeName(all bool) string {
+	if all {
+		return "everyone"
+	}
+	return "mine"
+}
+
+func (m Model) Issues() []JiraIssue { return m.issues }
+
+// Shown names the issues listed for the current scope and state, e.g.
+// "open issues (mine)" or "issues (everyone)".
+func (m Model) Shown() string {
+	return fmt.Sprintf("%s (%s)", m.state.Issues(), scopeName(m.all))
+}
+
+// Filtering reports whether the user is typing a filter, so keys belong
+// to the list.
+func (m Model) Filtering() bool { return m.list.FilterState() == list.Filtering }
+
+// SelectedIssue returns the highlighted issue, if it is a real one.
+func (m Model) SelectedIssue() (JiraIssue, bool) {
+	sel, ok := m.list.SelectedItem().(item)
+	if !ok || sel.key == "" {
+		return JiraIssue{}, false
+	}
+	for _, issue := range append(m.issues, m.pinnedExtra...) {
+		if issue.Key == sel.key {
+			return issue, true
+		}
+	}
+	return JiraIssue{}, false
+}
+func (m Model) Loading() bool { return m.loading }
+func (m Model) Err() error    { return m.err }
+
 // -- Messages --
 
-type issuesFetchedMsg []JiraIssue
-type errMsg error
+// Fetch results carry the fetch's id, so one superseded by a newer fetch
+// is dropped rather than overwriting the list.
+type issuesFetchedMsg struct {
+	JiraSearchResponse
+	id   int
+	took time.Duration // how long the search took, shown in the status line
+}
+type errMsg struct {
+	id  int
+	err error
+}
 
 // -- Commands --
 
-func fetchIssues() tea.Cmd {
-	return func() tea.Msg {
-		baseURL := os.Getenv("JIRA_URL")
-		email := os.Getenv("JIRA_EMAIL")
-		token := os.Getenv("JIRA_TOKEN")
+// MyJQL selects the issues assigned to the user.
+const MyJQL = "assignee=currentUser()"
+
+// defaultAllJQL is the "all issues" scope unless JIRA_ALL_JQL is set.
+const defaultAllJQL = "ORDER BY updated DESC"
 
-		if baseURL == "" || email == "" || token == "" {
+// stateClauses narrow a search to a state by status category, which
+// works whatever the workflow calls its statuses.
+var stateClauses = map[issuestate.State]string{
+	issuestate.Open:   "statusCategory != Done",
+	issuestate.Closed: "statusCategory = Done",
+}
+
+// scopeJQL returns the search for the current scope and state.
+func scopeJQL(all bool, state issuestate.State) string {
+	jql := MyJQL
+	if all {
+		jql = defaultAllJQL
+		if custom := os.Getenv("JIRA_ALL_JQL"); custom != "" {
+			jql = custom
+		}
+	}
+	return withClause(jql, stateClauses[state])
+}
+
+// withClause ANDs clause onto jql's conditions, keeping any ORDER BY last.
+func withClause(jql, clause string) string {
+	if clause == "" {
+		return jql
+	}
+	where, order := jql, ""
+	if i := strings.Index(strings.ToUpper(jql), "ORDER BY"); i >= 0 {
+		where, order = jql[:i], " "+jql[i:]
+	}
+	if where = strings.TrimSpace(where); where != "" {
+		clause = "(" + where + ") AND " + clause
+	}
+	return clause + order
+}
+
+// fetch starts a search of the current scope, cancelling any still running.
+func (m Model) fetch() tea.Cmd {
+	ctx, id := m.inflight.Start()
+	return fetchIssues(ctx, id, scopeJQL(m.all, m.state))
+}
+
+// Cancel stops the fetch in flight, for quitting.
+func (m Model) Cancel() { m.inflight.Cancel() }
+
+// ApplyConfig picks up Jira settings changed by /reload, such as the site,
+// credentials or JIRA_ALL_JQL, and searches again.
+func (m *Model) ApplyConfig(changed config.Changes) tea.Cmd {
+	if !changed.Any("JIRA_") {
+		return nil
+	}
+	m.list.Title = listTitle(m.all, m.state)
+	if !jiraapi.Configured() && !demo.Enabled() {
+		return nil
+	}
+	m.loading = true
+	return m.fetch()
+}
+
+// ShowAll starts the tab on the "all issues" scope, for --jql.
+func (m *Model) ShowAll() {
+	m.all = true
+	m.list.Title = listTitle(true, m.state)
+}
+
+func fetchIssues(ctx context.Context, id int, jql string) tea.Cmd {
+	return func() tea.Msg {
+		if !demo.Enabled() && !jiraapi.Configured() {
 			// Return nil or a special msg indicating no config
 			return nil
 		}
+		start := time.Now()
+		result, err := Search(ctx, jql)
+		if err != nil {
+			return errMsg{id, err}
+		}
+		return issuesFetchedMsg{result, id, time.Since(start)}
+	}
+}
 
-		// Search for assigned issues
-		url := fmt.Sprintf("%s/rest/api/3/search?jql=assignee=currentUser()", baseURL)
+// ErrNotConfigured is returned by Search when the Jira settings are missing.
+var ErrNotConfigured = errors.New("Jira is not configured: set JIRA_URL, JIRA_EMAIL and JIRA_TOKEN (or JIRA_AUTH=bearer with a PAT)")
+
+// Search runs jql against the configured Jira, following up to maxPages
+// pages. It is what the tab fetches with, exported for headless use.
+func Search(ctx context.Context, jql string) (JiraSearchResponse, error) {
+	var result JiraSearchResponse
+	if demo.Enabled() {
+		err := json.Unmarshal(demo.Fixture("jira"), &result)
+		return result, err
+	}
+	if !jiraapi.Configured() {
+		return result, ErrNotConfigured
+	}
+	baseURL := os.Getenv("JIRA_URL")
+	api := jiraapi.SearchAPI(baseURL)
+	auth := jiraapi.AuthHeader(jiraapi.AuthMode(), os.Getenv("JIRA_EMAIL"), config.Secret("JIRA_TOKEN"))
+	extra := extraFields()
 
-		req, _ := http.NewRequest("GET", url, nil)
-		auth := base64.StdEncoding.EncodeToString([]byte(email + ":" + token))
-		req.Header.Add("Authorization", "Basic "+auth)
-		req.Header.Add("Accept", "application/json")
+	client, err := httpclient.NewWithTLS(10*time.Second, httpclient.TLSFromEnv("JIRA"))
+	if err != nil {
+		return result, err
+	}
 
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Do(req)
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		req := newSearchRequest(baseURL, auth, api, jql, extra, cursor).WithContext(ctx)
+		pageResult, err := fetchPage(client, req)
 		if err != nil {
-			return errMsg(err)
+			return result, err
+		}
+		result.Issues = append(result.Issues, pageResult.Issues...)
+		if result.Names == nil {
+			result.Names = pageResult.Names
+		}
+		if cursor = nextCursor(api, pageResult); cursor == "" {
+			break
 		}
-		defer resp.Body.Close()
+	}
+	return result, nil
+}
+
+// maxPages bounds how many search pages are fetched per refresh, so a
+// refresh returns at most maxPages*maxResults() issues.
+const maxPages = 5
+
+// Issues per search page: JIRA_MAX_RESULTS, defaulting to Jira's own page
+// size and capped at the most Cloud returns per page.
+const (
+	defaultMaxResults = 50
+	maxMaxResults     = 100
+)
+
+func maxResults() int {
+	if n, err := strconv.Atoi(os.Getenv("JIRA_MAX_RESULTS")); err == nil && n > 0 {
+		return min(n, maxMaxResults)
+	}
+	return defaultMaxResults
+}
 
-		if resp.StatusCode != 200 {
-			return errMsg(fmt.Errorf("API Error: %s", resp.Status))
+// searchURL builds the search URL for jql on the given search API.
+// cursor is the page to fetch: a startAt offset for the legacy API, a
+// nextPageToken for the enhanced one, or "" for the first page.
+func searchURL(baseURL, api, jql string, extra []string, cursor string) string {
+	q := url.Values{}
+	q.Set("jql", jql)
+	q.Set("maxResults", strconv.Itoa(maxResults()))
+	// Name the fields on both APIs: the enhanced search only returns ids
+	// otherwise, and the legacy one returns every field, which Cloud warns
+	// about and which bloats the response
+	q.Set("fields", strings.Join(issueFields(extra), ","))
+	if len(extra) > 0 {
+		q.Set("expand", "names")
+	}
+	if cursor != "" {
+		if api == jiraapi.SearchJQL {
+			q.Set("nextPageToken", cursor)
+		} else {
+			q.Set("startAt", cursor)
 		}
+	}
+	return baseURL + jiraapi.SearchPath(api) + "?" + q.Encode()
+}
 
-		var result JiraSearchResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return errMsg(err)
+// nextCursor returns the cursor for the page after result, or "" when
+// result was the last page.
+func nextCursor(api string, result JiraSearchResponse) string {
+	if api == jiraapi.SearchJQL {
+		if result.IsLast {
+			return ""
 		}
+		return result.NextPageToken
+	}
+	next := result.StartAt + len(result.Issues)
+	if len(result.Issues) == 0 || next >= result.Total {
+		return ""
+	}
+	return strconv.Itoa(next)
+}
+
+// newSearchRequest builds a search request; auth is the Authorization
+// header value (see jiraapi.AuthHeader).
+func newSearchRequest(baseURL, auth, api, jql string, extra []string, cursor string) *http.Request {
+	req, _ := http.NewRequest("GET", searchURL(baseURL, api, jql, extra, cursor), nil)
+	req.Header.Add("Authorization", auth)
+	req.Header.Add("Accept", "application/json")
+	httpclient.SetHeaders(req, httpclient.Jira)
+	return req
+}
+
+func fetchPage(client *http.Client, req *http.Request) (JiraSearchResponse, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return JiraSearchResponse{}, err
+	}
+	defer resp.Body.Close()
+	return decodeSearchResponse(resp)
+}
 
-		return issuesFetchedMsg(result.Issues)
+func decodeSearchResponse(resp *http.Response) (JiraSearchResponse, error) {
+	var result JiraSearchResponse
+	if resp.StatusCode != 200 {
+		return result, jiraapi.StatusError(resp)
 	}
+	err := httpclient.DecodeJSON(resp, httpclient.Jira, &result)
+	return result, err
+}
+
+// issueItem maps an issue to a list item, rendering extra fields into the
+// description. names maps field ids to display names.
+func issueItem(issue JiraIssue, names map[string]string, extra []string) item {
+	var desc string
+	for _, id := range extra {
+		v := formatFieldValue(issue.Fields.Extra[id])
+		if v == "" {
+			continue
+		}
+		name := names[id]
+		if name == "" {
+			name = id
+		}
+		desc += fmt.Sprintf(" • %s: %s", name, v)
+	}
+	return item{
+		title:    fmt.Sprintf("%s %s", issue.Key, issue.Fields.Summary),
+		desc:     desc,
+		key:      issue.Key,
+		status:   issue.Fields.Status.Name,
+		category: issue.Fields.Status.StatusCategory.Key,
+	}
+}
+
+// baseFields are the fields JiraIssue decodes. The status comes with its
+// statusCategory, which colors it in the list.
+var baseFields = []string{"summary", "status", "description"}
+
+// issueFields is the field list for every issue request: the base fields
+// plus extra (see extraFields).
+func issueFields(extra []string) []string {
+	return append(slices.Clip(baseFields), extra...)
+}
+
+// extraFields returns the additional field ids from JIRA_EXTRA_FIELDS.
+func extraFields() []string {
+	var fields []string
+	for _, f := range strings.Split(os.Getenv("JIRA_EXTRA_FIELDS"), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// formatFieldValue renders an arbitrary Jira field value. Missing or
+// unrecognized shapes render as "" so the caller can skip them.
+func formatFieldValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]any:
+		// Objects like components, users, options
+		for _, k := range []string{"name", "displayName", "value", "key"} {
+			if s, ok := v[k].(string); ok {
+				return s
+			}
+		}
+	case []any:
+		var parts []string
+		for _, e := range v {
+			if s := formatFieldValue(e); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return ""
 }
 
 // -- Update --
 
 func (m Model) Init() tea.Cmd {
-	return fetchIssues()
+	if !jiraapi.Configured() && !demo.Enabled() {
+		return m.fetch()
+	}
+	return tea.Batch(m.fetch(), m.poller.Start())
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.detail != nil {
+			return m.updateDetail(msg)
+		}
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.String() {
+		case "enter":
+			return m, m.openDetail()
+		case "O":
+			url := os.Getenv("JIRA_URL")
+			if url == "" {
+				return m, m.list.NewStatusMessage("JIRA_URL is not set")
+			}
+			if err := browser.Open(url); err != nil {
+				return m, m.list.NewStatusMessage(fmt.Sprintf("Could not open browser: %v", err))
+			}
+			return m, m.list.NewStatusMessage("Opened " + url)
+		case "a":
+			if !jiraapi.Configured() && !demo.Enabled() {
+				break
+			}
+			m.all = !m.all
+			m.loading = true
+			m.list.Title = listTitle(m.all, m.state)
+			return m, tea.Batch(m.fetch(), m.list.NewStatusMessage("Loading…"))
+		case "x":
+			if !jiraapi.Configured() && !demo.Enabled() {
+				break
+			}
+			m.state = m.state.Next()
+			m.loading = true
+			m.list.Title = listTitle(m.all, m.state)
+			return m, tea.Batch(m.fetch(), m.list.NewStatusMessage("Loading "+m.state.Issues()+"…"))
+		case "p":
+			return m, m.togglePin()
+		}
+
 	case tea.WindowSizeMsg:
-		m.list.SetSize(msg.Width, msg.Height)
+		m.SetSize(msg.Width, msg.Height)
+
+	case refresh.TickMsg:
+		return m, m.poller.Tick(msg, m.loading, func() tea.Cmd {
+			m.loading = true
+			return m.fetch()
+		})
 
 	case issuesFetchedMsg:
-		var items []list.Item
-		for _, issue := range msg {
-			items = append(items, item{
-				title: fmt.Sprintf("%s %s", issue.Key, issue.Fields.Summary),
-				desc:  fmt.Sprintf("Status: %s", issue.Fields.Status.Name),
-			})
-		}
-		if len(items) > 0 {
-			m.list.SetItems(items)
-		} else {
-			m.list.SetItems([]list.Item{item{title: "No issues found", desc: "You have no assigned issues."}})
+		if !m.inflight.Current(msg.id) {
+			return m, nil
 		}
+		m.announceNewIssues(msg.Issues)
+		m.issues = msg.Issues
+		m.names = msg.Names
+		m.took = msg.took
+		m.setItems()
 		m.loading = false
+		m.err = nil
+		next := m.poller.Done(nil)
+		m.list, cmd = m.list.Update(msg)
+		return m, tea.Batch(cmd, next, fetchPinned(missingPinned(m.pinned, m.issues)))
+
+	case pinnedFetchedMsg:
+		m.pinnedExtra = msg.issues
+		m.setItems()
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage(fmt.Sprintf("Could not load pinned issue %v", msg.err))
+		}
 
 	case errMsg:
-		m.err = msg
+		if !m.inflight.Current(msg.id) {
+			return m, nil
+		}
 		m.loading = false
-		m.list.SetItems([]list.Item{item{title: "Error", desc: msg.Error()}})
+		if m.poller.Polling() {
+			next := m.poller.Done(msg.err)
+			if m.issues != nil {
+				// Keep showing the last good list while offline or rate-limited
+				return m, tea.Batch(next, m.list.NewStatusMessage(fmt.Sprintf("Auto-refresh failed, retrying later: %v", msg.err)))
+			}
+			cmd = next
+		}
+		m.err = msg.err
+		m.issues = nil
+		m.count = 0
+		m.list.SetItems([]list.Item{item{title: "Error", desc: msg.err.Error()}})
 	}
 
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	var listCmd tea.Cmd
+	m.list, listCmd = m.list.Update(msg)
+	return m, tea.Batch(cmd, listCmd)
 }
 
 func (m Model) View() string {
-	return lipgloss.NewStyle().Margin(1, 2).Render(m.list.View())
+	if m.detail != nil {
+		return m.detailView()
+	}
+	// Clip to the list's width: its title bar can run a column over when
+	// cut short, and the status line isn't cut at all
+	body := lipgloss.NewStyle().MaxWidth(m.list.Width()).Render(m.list.View() + "\n" + m.statusLine())
+	return layout.Frame.Render(body)
+}
+
+// statusLine shows how many issues match the current filter plus a filter hint.
+func (m Model) statusLine() string {
+	total := m.count
+	if total == 0 {
+		return ""
+	}
+	switch m.list.FilterState() {
+	case list.Filtering:
+		return statusStyle.Render(fmt.Sprintf("%d of %d issues • enter apply • esc cancel", len(m.list.VisibleItems()), total))
+	case list.FilterApplied:
+		return statusStyle.Render(fmt.Sprintf("%d of %d issues • esc clear filter", len(m.list.VisibleItems()), total))
+	}
+	return statusStyle.Render(fmt.Sprintf("%d issues • fetched in %s • enter view • / filter • a scope • x open/closed/all", total, m.took.Round(10*time.Millisecond)))
 }
 
 func (m *Model) SetSize(width, height int) {
-	m.list.SetSize(width, height)
+	m.width, m.height = width, height
+	// Leave room for the margin, so long titles are cut before the edge,
+	// and for the status line
+	m.list.SetSize(max(width-layout.Margin, 0), max(height-1, 0))
+	if m.detail != nil {
+		m.detail.view.SetSize(width, height)
+	}
 }
@@ -0,0 +1,197 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"termiflow/jiraapi"
+)
+
+func TestSearchURL(t *testing.T) {
+	t.Setenv("JIRA_MAX_RESULTS", "25")
+	tests := []struct {
+		name   string
+		api    string
+		extra  []string
+		cursor string
+		path   string
+		want   url.Values
+	}{
+		{"legacy first page", jiraapi.SearchLegacy, nil, "", "/rest/api/3/search",
+			url.Values{"jql": {MyJQL}, "maxResults": {"25"}, "fields": {"summary,status,description"}}},
+		{"legacy next page", jiraapi.SearchLegacy, nil, "50", "/rest/api/3/search",
+			url.Values{"jql": {MyJQL}, "maxResults": {"25"}, "fields": {"summary,status,description"}, "startAt": {"50"}}},
+		{"enhanced next page", jiraapi.SearchJQL, nil, "tok", "/rest/api/3/search/jql",
+			url.Values{"jql": {MyJQL}, "maxResults": {"25"}, "fields": {"summary,status,description"}, "nextPageToken": {"tok"}}},
+		{"extra fields", jiraapi.SearchJQL, []string{"customfield_10016"}, "", "/rest/api/3/search/jql",
+			url.Values{"jql": {MyJQL}, "maxResults": {"25"}, "fields": {"summary,status,description,customfield_10016"}, "expand": {"names"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(searchURL("https://jira.example.com", tt.api, MyJQL, tt.extra, tt.cursor))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Host != "jira.example.com" || u.Path != tt.path {
+				t.Errorf("URL = %s, want host jira.example.com and path %s", u, tt.path)
+			}
+			if got := u.Query(); got.Encode() != tt.want.Encode() {
+				t.Errorf("query = %s, want %s", got.Encode(), tt.want.Encode())
+			}
+		})
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	page := func(n int) []JiraIssue { return make([]JiraIssue, n) }
+	tests := []struct {
+		name   string
+		api    string
+		result JiraSearchResponse
+		want   string
+	}{
+		{"legacy more", jiraapi.SearchLegacy, JiraSearchResponse{Issues: page(50), StartAt: 0, Total: 120}, "50"},
+		{"legacy last", jiraapi.SearchLegacy, JiraSearchResponse{Issues: page(20), StartAt: 100, Total: 120}, ""},
+		{"legacy empty", jiraapi.SearchLegacy, JiraSearchResponse{StartAt: 0, Total: 120}, ""},
+		{"enhanced more", jiraapi.SearchJQL, JiraSearchResponse{Issues: page(50), NextPageToken: "tok"}, "tok"},
+		{"enhanced last", jiraapi.SearchJQL, JiraSearchResponse{Issues: page(5), NextPageToken: "tok", IsLast: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCursor(tt.api, tt.result); got != tt.want {
+				t.Errorf("nextCursor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithClause(t *testing.T) {
+	tests := []struct {
+		jql, clause, want string
+	}{
+		{MyJQL, "", MyJQL},
+		{MyJQL, "statusCategory != Done", "(assignee=currentUser()) AND statusCategory != Done"},
+		{"ORDER BY updated DESC", "statusCategory = Done", "statusCategory = Done ORDER BY updated DESC"},
+		{"project = WEB order by created", "statusCategory != Done", "(project = WEB) AND statusCategory != Done order by created"},
+	}
+	for _, tt := range tests {
+		if got := withClause(tt.jql, tt.clause); got != tt.want {
+			t.Errorf("withClause(%q, %q) = %q, want %q", tt.jql, tt.clause, got, tt.want)
+		}
+	}
+	if got := strings.Count(withClause("a = 1 ORDER BY b", "c = 2"), "ORDER BY"); got != 1 {
+		t.Errorf("ORDER BY appears %d times, want once", got)
+	}
+}
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestDecodeSearchResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		keys    []string
+		names   map[string]string
+		wantErr bool
+	}{
+		{"legacy page", response(200, `{"startAt": 0, "total": 2, "issues": [
+			{"key": "PROJ-1", "fields": {"summary": "a", "status": {"name": "To Do", "statusCategory": {"key": "new"}}}},
+			{"key": "PROJ-2", "fields": {"summary": "b", "status": {"name": "Done", "statusCategory": {"key": "done"}}}}]}`),
+			[]string{"PROJ-1", "PROJ-2"}, nil, false},
+		{"with names", response(200, `{"issues": [{"key": "PROJ-3", "fields": {"customfield_1": 5}}], "names": {"customfield_1": "Story Points"}, "isLast": true}`),
+			[]string{"PROJ-3"}, map[string]string{"customfield_1": "Story Points"}, false},
+		{"unauthorized", response(401, `{"errorMessages": ["no"]}`), nil, nil, true},
+		{"malformed", response(200, `{"issues": [`), nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := decodeSearchResponse(tt.resp)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, issue := range result.Issues {
+				keys = append(keys, issue.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) || !reflect.DeepEqual(result.Names, tt.names) {
+				t.Errorf("keys %v names %v, want %v %v", keys, result.Names, tt.keys, tt.names)
+			}
+		})
+	}
+}
+
+func TestIssueItem(t *testing.T) {
+	var issue JiraIssue
+	err := json.Unmarshal([]byte(`{"key": "PROJ-7", "fields": {
+		"summary": "Login fails",
+		"status": {"name": "In Progress", "statusCategory": {"key": "indeterminate"}},
+		"customfield_1": 3,
+		"components": [{"name": "api"}, {"name": "web"}],
+		"assignee": {"displayName": "Ana"},
+		"customfield_2": null}}`), &issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]string{"customfield_1": "Story Points"}
+	tests := []struct {
+		name  string
+		extra []string
+		desc  string
+	}{
+		{"no extra fields", nil, ""},
+		{"named and unnamed", []string{"customfield_1", "components"}, " • Story Points: 3 • components: api, web"},
+		{"object and empty", []string{"assignee", "customfield_2", "missing"}, " • assignee: Ana"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := issueItem(issue, names, tt.extra)
+			if it.title != "PROJ-7 Login fails" || it.key != "PROJ-7" || it.status != "In Progress" || it.category != "indeterminate" {
+				t.Errorf("item = %+v", it)
+			}
+			if it.desc != tt.desc {
+				t.Errorf("desc = %q, want %q", it.desc, tt.desc)
+			}
+		})
+	}
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{2.5, "2.5"},
+		{float64(8), "8"},
+		{true, "true"},
+		{map[string]any{"value": "High"}, "High"},
+		{map[string]any{"displayName": "Ana", "key": "ana"}, "Ana"},
+		{map[string]any{"id": "10"}, ""},
+		{[]any{"a", map[string]any{"name": "b"}, nil}, "a, b"},
+	}
+	for _, tt := range tests {
+		if got := formatFieldValue(tt.v); got != tt.want {
+			t.Errorf("formatFieldValue(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"termiflow/demo"
+	"termiflow/httpclient"
+	"termiflow/jiraapi"
+	"termiflow/storage"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Pinned Issues --
+
+// pinnedFile lists pinned issue keys in pin order.
+const pinnedFile = "pinned_jira.json"
+
+func loadPinned() []string {
+	var keys []string
+	_ = storage.LoadJSON(pinnedFile, &keys) // unreadable file: start with no pins
+	return keys
+}
+
+type pinnedFetchedMsg struct {
+	issues []JiraIssue
+	err    error
+}
+
+// fetchPinned loads pinned issues that the current search didn't return.
+func fetchPinned(keys []string) tea.Cmd {
+	if len(keys) == 0 || demo.Enabled() || !jiraapi.Configured() {
+		return nil
+	}
+	return func() tea.Msg {
+		client, err := httpclient.NewWithTLS(10*time.Second, httpclient.TLSFromEnv("JIRA"))
+		if err != nil {
+			return pinnedFetchedMsg{err: err}
+		}
+		var msg pinnedFetchedMsg
+		for _, key := range keys {
+			issue, err := fetchIssue(client, key)
+			if err != nil {
+				msg.err = fmt.Errorf("%s: %w", key, err)
+				continue
+			}
+			msg.issues = append(msg.issues, issue)
+		}
+		return msg
+	}
+}
+
+func fetchIssue(client *http.Client, key string) (JiraIssue, error) {
+	q := url.Values{}
+	q.Set("fields", strings.Join(issueFields(extraFields()), ","))
+	req, _ := http.NewRequest("GET", os.Getenv("JIRA_URL")+jiraapi.IssuePath(key)+"?"+q.Encode(), nil)
+	jiraapi.SetAuth(req)
+	req.Header.Add("Accept", "application/json")
+	httpclient.SetHeaders(req, httpclient.Jira)
+
+	var issue JiraIssue
+	resp, err := client.Do(req)
+	if err != nil {
+		return issue, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// On the issue endpoint this means the issue, not the site, is missing
+		return issue, errors.New("issue not found, or you don't have permission to see it")
+	case resp.StatusCode != 200:
+		return issue, jiraapi.StatusError(resp)
+	}
+	err = httpclient.DecodeJSON(resp, httpclient.Jira, &issue)
+	return issue, err
+}
+
+// missingPinned returns the pinned keys not among issues.
+func missingPinned(pinned []string, issues []JiraIssue) []string {
+	var missing []string
+	for _, key := range pinned {
+		if !slices.ContainsFunc(issues, func(i JiraIssue) bool { return i.Key == key }) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// togglePin pins or unpins the selected issue and saves the pin list.
+func (m *Model) togglePin() tea.Cmd {
+	sel, ok := m.list.SelectedItem().(item)
+	if !ok || sel.key == "" {
+		return nil
+	}
+	status := "Pinned " + sel.key
+	if i := slices.Index(m.pinned, sel.key); i >= 0 {
+		m.pinned = slices.Delete(m.pinned, i, i+1)
+		status = "Unpinned " + sel.key
+	} else {
+		m.pinned = append(m.pinned, sel.key)
+	}
+	m.setItems()
+	if err := storage.SaveJSON(pinnedFile, m.pinned); err != nil {
+		status = fmt.Sprintf("Could not save pins: %v", err)
+	}
+	return m.list.NewStatusMessage(status)
+}
+
+// setItems fills the list with pinned issues first, in pin order, followed
+// by the rest of the search results. The selected issue stays selected
+// wherever it moved.
+func (m *Model) setItems() {
+	selected, _ := m.list.SelectedItem().(item)
+	extra := extraFields()
+	byKey := map[string]JiraIssue{}
+	for _, issue := range m.pinnedExtra {
+		byKey[issue.Key] = issue
+	}
+	for _, issue := range m.issues {
+		byKey[issue.Key] = issue
+	}
+
+	var items []list.Item
+	for _, key := range m.pinned {
+		if issue, ok := byKey[key]; ok {
+			it := issueItem(issue, m.names, extra)
+			it.pinned = true
+			items = append(items, it)
+		}
+	}
+	for _, issue := range m.issues {
+		if !slices.Contains(m.pinned, issue.Key) {
+			items = append(items, issueItem(issue, m.names, extra))
+		}
+	}
+
+	m.count = len(items)
+	if len(items) > 0 {
+		m.list.SetItems(items)
+		// Select indexes visible items, so filtered lists are left alone
+		if m.list.FilterState() == list.Unfiltered && selected.key != "" {
+			if i := slices.IndexFunc(items, func(it list.Item) bool { return it.(item).key == selected.key }); i >= 0 {
+				m.list.Select(i)
+			}
+		}
+		return
+	}
+	desc := "You have no assigned " + m.state.Issues() + "."
+	if m.all {
+		desc = "Nothing matches " + scopeJQL(true, m.state)
+	}
+	m.list.SetItems([]list.Item{item{title: "No issues found", desc: desc}})
+}
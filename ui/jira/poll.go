@@ -0,0 +1,26 @@
+package jira
+
+import (
+	"termiflow/notify"
+	"termiflow/ui/issuestate"
+)
+
+// -- Auto-Refresh --
+
+// announceNewIssues notifies about issues an auto-refresh found that the
+// previous fetch didn't have. Only open issues are news: in the closed
+// list a new entry is one that was just resolved.
+func (m Model) announceNewIssues(issues []JiraIssue) {
+	if !m.poller.Polling() || m.issues == nil || m.state == issuestate.Closed {
+		return
+	}
+	known := map[string]bool{}
+	for _, issue := range m.issues {
+		known[issue.Key] = true
+	}
+	for _, issue := range issues {
+		if !known[issue.Key] {
+			notify.Send("New Jira issue "+issue.Key, issue.Fields.Summary)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Package layout is the frame the Jira and GitHub tabs draw their lists,
+// issue views and forms in, so their sizes agree with what View draws.
+package layout
+
+import "github.com/charmbracelet/lipgloss"
+
+// Frame keeps a blank line above and below a view and two columns to
+// either side.
+var Frame = lipgloss.NewStyle().Margin(1, 2)
+
+// Margin is the horizontal space Frame takes around a view; sizes leave
+// room for it so long lines are cut before the edge.
+const Margin = 4
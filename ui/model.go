@@ -1,9 +1,17 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
 	"strings"
 
+	"termiflow/demo"
+	"termiflow/storage"
 	"termiflow/ui/chat"
+	"termiflow/ui/dashboard"
+	"termiflow/ui/focus"
 	"termiflow/ui/github"
 	"termiflow/ui/jira"
 	"termiflow/ui/shell"
@@ -15,7 +23,8 @@ import (
 type sessionState int
 
 const (
-	viewShell sessionState = iota
+	viewDashboard sessionState = iota
+	viewShell
 	viewJira
 	viewGitHub
 	viewChat
@@ -23,6 +32,7 @@ const (
 
 var (
 	docStyle   = lipgloss.NewStyle().Margin(1, 2)
+	focusStyle = lipgloss.NewStyle().Margin(0, 1)
 	tabsBorder = lipgloss.Border{
 		Top:         "─",
 		Bottom:      "─",
@@ -40,31 +50,101 @@ var (
 			Padding(0, 1)
 	activeTabStyle   = tabStyle.Copy().Border(tabsBorder, true)
 	inactiveTabStyle = tabStyle.Copy().Border(tabsBorder, true).BorderForeground(lipgloss.Color("240"))
+	demoBadgeStyle   = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#D75F00")).
+				Padding(0, 1).
+				MarginLeft(2)
+)
+
+// Below this size the tab row wraps and the views have no room left, so
+// View shows a notice instead until the terminal grows.
+const (
+	minWidth  = 60
+	minHeight = 15
 )
 
 type Model struct {
 	state sessionState
 	tabs  []string
 
-	shell  shell.Model
-	jira   jira.Model
-	github github.Model
-	chat   chat.Model
+	dashboard dashboard.Model
+	shell     shell.Model
+	jira      jira.Model
+	github    github.Model
+	chat      chat.Model
 
 	width  int
 	height int
+	focus  bool // hide the tab row to give the active view the whole screen
+
+	flash   string // short confirmation shown next to the tabs
+	flashID int    // identifies the latest flash so older timers don't clear it
+
+	seeded tea.Cmd // follow-up from Seed, run by Init
+
+	shutdownErr error
+}
+
+// stateFile remembers UI state (like the last tab) between runs.
+const stateFile = "state.json"
+
+type uiState struct {
+	LastTab string `json:"last_tab"`
 }
 
+// tabNames are the tabs in order, matching sessionState.
+var tabNames = []string{"Dashboard", "Shell", "Jira", "GitHub", "Chat"}
+
 func New() Model {
-	tabs := []string{"Shell", "Jira", "GitHub", "Chat"}
-	return Model{
-		state:  viewShell,
-		tabs:   tabs,
-		shell:  shell.New(),
-		jira:   jira.New(),
-		github: github.New(),
-		chat:   chat.New(),
+	tabs := slices.Clone(tabNames)
+	m := Model{
+		state:     defaultTab(tabs),
+		tabs:      tabs,
+		dashboard: dashboard.New(),
+		shell:     shell.New(),
+		jira:      jira.New(),
+		github:    github.New(),
+		chat:      chat.New(),
+	}
+	if f := m.focusable(m.state); f != nil {
+		f.Focus()
+	}
+	return m
+}
+
+// KnownTab reports whether name (any case) is a tab or "last", the values
+// TERMIFLOW_DEFAULT_TAB accepts.
+func KnownTab(name string) bool {
+	return strings.EqualFold(name, "last") || slices.ContainsFunc(tabNames, func(t string) bool {
+		return strings.EqualFold(t, name)
+	})
+}
+
+// ShowAllJira opens the Jira tab on the "all issues" scope, so a
+// --jql search shows up instead of the user's own issues.
+func (m Model) ShowAllJira() Model {
+	m.jira.ShowAll()
+	return m
+}
+
+// defaultTab returns the landing tab named by TERMIFLOW_DEFAULT_TAB
+// (e.g. "shell", or "last" for the tab open at the last quit), falling back
+// to the dashboard.
+func defaultTab(tabs []string) sessionState {
+	name := os.Getenv("TERMIFLOW_DEFAULT_TAB")
+	if strings.EqualFold(name, "last") {
+		var st uiState
+		_ = storage.LoadJSON(stateFile, &st)
+		name = st.LastTab
+	}
+	for i, t := range tabs {
+		if strings.EqualFold(t, name) {
+			return sessionState(i)
+		}
 	}
+	return viewDashboard
 }
 
 func (m Model) Init() tea.Cmd {
@@ -73,9 +153,26 @@ func (m Model) Init() tea.Cmd {
 		m.jira.Init(),
 		m.github.Init(),
 		m.chat.Init(),
+		m.seeded,
 	)
 }
 
+// resize hands each sub-model the space left after the header. Focus mode
+// drops the tab row and most of the margin.
+func (m *Model) resize() {
+	width, height := m.width-4, m.height-5 // docStyle's margin; approx header height
+	if m.focus {
+		width, height = m.width-2, m.height
+	}
+	width, height = max(width, 0), max(height, 0)
+
+	m.dashboard.SetSize(width, height)
+	m.shell.SetSize(width, height)
+	m.jira.SetSize(width, height)
+	m.github.SetSize(width, height)
+	m.chat.SetSize(width, height)
+}
+
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
@@ -84,53 +181,159 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
+			if m.state == viewShell && m.shell.Running() {
+				break // interrupts the running command instead
+			}
+			m.shutdownErr = m.shutdown()
 			return m, tea.Quit
-		case "tab":
-			m.state = (m.state + 1) % sessionState(len(m.tabs))
+		case "alt+n":
+			return m, m.switchTab(m.state + 1)
+		case "alt+p":
+			return m, m.switchTab(m.state + sessionState(len(m.tabs)) - 1)
+		case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5":
+			return m, m.switchTab(sessionState(msg.String()[len("alt+")] - '1'))
+		case "ctrl+f":
+			m.focus = !m.focus
+			m.resize()
 			return m, nil
+		case "c":
+			if cmd, ok := m.askAboutSelection(); ok {
+				return m, cmd
+			}
+		case "ctrl+y":
+			return m, m.copyLastError()
+		case "alt+i":
+			if m.state == viewShell {
+				return m, m.fileLastCommand()
+			}
+		case "alt+y":
+			if m.state == viewShell {
+				return m, m.copyTranscript()
+			}
 		}
+	case chat.ReloadedMsg:
+		return m, tea.Batch(
+			m.shell.ApplyConfig(msg.Changed),
+			m.jira.ApplyConfig(msg.Changed),
+			m.github.ApplyConfig(msg.Changed),
+			m.chat.ApplyConfig(msg.Changed),
+		)
+	case chat.ShowReferenceMsg:
+		if msg.Ref.Key != "" {
+			return m, tea.Batch(m.switchTab(viewJira), m.jira.ShowIssue(msg.Ref.Key))
+		}
+		return m, tea.Batch(m.switchTab(viewGitHub), m.github.ShowIssue(msg.Ref.Repo, msg.Ref.Number))
+	case flashDoneMsg:
+		if msg.id == m.flashID {
+			m.flash = ""
+		}
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-
-		// Pass size down to sub-models
-		// Note: We might want closer control over layout later
-		contentHeight := msg.Height - 5 // Approx header height
-
-		// Update Jira and Github list sizes
-		m.jira.SetSize(msg.Width, contentHeight)
-		m.github.SetSize(msg.Width, contentHeight)
-		m.chat.SetSize(msg.Width, contentHeight)
-
-		// Shell handles its own sizing in Update usually, but let's pass it if needed
-		// For now shell Update handles WindowSizeMsg directly
+		// The root owns layout; sub-models get their share via SetSize
+		m.resize()
+		return m, nil
 	}
 
-	// Update the active model
-	switch m.state {
-	case viewShell:
+	// Keys only go to the active model. Everything else (fetch results,
+	// blinks) is broadcast so background tabs and the dashboard stay up
+	// to date.
+	_, isKey := msg.(tea.KeyMsg)
+	if !isKey || m.state == viewShell {
 		m.shell, cmd = m.shell.Update(msg)
 		cmds = append(cmds, cmd)
-	case viewJira:
+	}
+	if !isKey || m.state == viewJira {
 		m.jira, cmd = m.jira.Update(msg)
 		cmds = append(cmds, cmd)
-	case viewGitHub:
+	}
+	if !isKey || m.state == viewGitHub {
 		m.github, cmd = m.github.Update(msg)
 		cmds = append(cmds, cmd)
-	case viewChat:
+	}
+	if !isKey || m.state == viewChat {
 		m.chat, cmd = m.chat.Update(msg)
 		cmds = append(cmds, cmd)
 	}
 
+	// Looking at the GitHub tab counts as viewing its new issues
+	if m.github.Focused() {
+		cmds = append(cmds, m.github.MarkSeen())
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// switchTab shows tab i, wrapping around. Tab and shift+tab stay with the
+// active view, which uses them to move focus between its own panes.
+func (m *Model) switchTab(i sessionState) tea.Cmd {
+	if f := m.focusable(m.state); f != nil {
+		f.Blur()
+	}
+	m.state = i % sessionState(len(m.tabs))
+	if f := m.focusable(m.state); f != nil {
+		return f.Focus()
+	}
+	return nil
+}
+
+// focusable returns tab i if it needs to know when it's shown: the Shell
+// tab notifies about commands finishing out of sight and the GitHub tab
+// counts being looked at as viewing its new issues.
+func (m *Model) focusable(i sessionState) focus.Focusable {
+	switch i {
+	case viewShell:
+		return &m.shell
+	case viewGitHub:
+		return &m.github
+	}
+	return nil
+}
+
+// shutdown stops fetches still in flight and gives every sub-model a chance
+// to persist its state before the program exits. All hooks run even if one
+// fails.
+func (m Model) shutdown() error {
+	m.jira.Cancel()
+	m.github.Cancel()
+	return errors.Join(
+		storage.SaveJSON(stateFile, uiState{LastTab: m.tabs[m.state]}),
+		m.chat.Shutdown(),
+		m.shell.Shutdown(),
+	)
+}
+
+// ShutdownErr reports any error from flushing state on quit.
+func (m Model) ShutdownErr() error {
+	return m.shutdownErr
+}
+
+// tooSmall reports whether the terminal is below the minimum size. Before
+// the first WindowSizeMsg the size is unknown and assumed to fit.
+func (m Model) tooSmall() bool {
+	return m.width > 0 && (m.width < minWidth || m.height < minHeight)
+}
+
 func (m Model) View() string {
+	if m.width == 0 {
+		// Nothing is laid out until the first WindowSizeMsg, which Bubble
+		// Tea sends right after start; a frame before it would be misdrawn
+		return ""
+	}
+	if m.tooSmall() {
+		return lipgloss.NewStyle().Width(m.width).Render(
+			fmt.Sprintf("Terminal too small (need ≥ %dx%d, have %dx%d)", minWidth, minHeight, m.width, m.height))
+	}
+
 	doc := strings.Builder{}
 
 	// Render Tabs
 	var renderedTabs []string
 	for i, t := range m.tabs {
+		if sessionState(i) == viewGitHub && m.github.NewCount() > 0 {
+			t = fmt.Sprintf("%s •%d", t, m.github.NewCount())
+		}
 		var style lipgloss.Style
 		if sessionState(i) == m.state {
 			style = activeTabStyle
@@ -140,12 +343,23 @@ func (m Model) View() string {
 		renderedTabs = append(renderedTabs, style.Render(t))
 	}
 
-	row := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
-	doc.WriteString(row)
-	doc.WriteString("\n\n")
+	if demo.Enabled() {
+		renderedTabs = append(renderedTabs, demoBadgeStyle.Render("DEMO MODE"))
+	}
+	if m.flash != "" {
+		renderedTabs = append(renderedTabs, flashStyle.Render(m.flash))
+	}
+
+	row := lipgloss.JoinHorizontal(lipgloss.Center, renderedTabs...)
+	if !m.focus {
+		doc.WriteString(row)
+		doc.WriteString("\n\n")
+	}
 
 	// Render Active View
 	switch m.state {
+	case viewDashboard:
+		doc.WriteString(m.dashboard.View(m.dashboardPanels()))
 	case viewShell:
 		doc.WriteString(m.shell.View())
 	case viewJira:
@@ -156,5 +370,8 @@ func (m Model) View() string {
 		doc.WriteString(m.chat.View())
 	}
 
+	if m.focus {
+		return focusStyle.Render(doc.String())
+	}
 	return docStyle.Render(doc.String())
 }
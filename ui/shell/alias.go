@@ -0,0 +1,144 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"termiflow/storage"
+)
+
+// -- Aliases --
+
+// aliasFile stores aliases one per line in shell syntax: alias gs='git status'
+const aliasFile = "aliases"
+
+func loadAliases() (map[string]string, error) {
+	aliases := map[string]string{}
+	path, err := storage.Path(aliasFile)
+	if err != nil {
+		return aliases, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return aliases, nil
+	}
+	if err != nil {
+		return aliases, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, cmd, ok := parseAlias(strings.TrimPrefix(line, "alias ")); ok {
+			aliases[name] = cmd
+		}
+	}
+	return aliases, scanner.Err()
+}
+
+func saveAliases(aliases map[string]string) error {
+	path, err := storage.Path(aliasFile)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, name := range sortedNames(aliases) {
+		sb.WriteString(formatAlias(name, aliases[name]) + "\n")
+	}
+	return storage.WriteFileAtomic(path, []byte(sb.String()), 0o600)
+}
+
+// parseAlias parses `name='command'` (quotes optional).
+func parseAlias(def string) (string, string, bool) {
+	name, cmd, ok := strings.Cut(def, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", false
+	}
+	cmd = strings.TrimSpace(cmd)
+	if len(cmd) >= 2 && (cmd[0] == '\'' || cmd[0] == '"') && cmd[len(cmd)-1] == cmd[0] {
+		cmd = cmd[1 : len(cmd)-1]
+	}
+	return name, cmd, cmd != ""
+}
+
+func formatAlias(name, cmd string) string {
+	return fmt.Sprintf("alias %s='%s'", name, cmd)
+}
+
+func sortedNames(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandAliases replaces the first word of input with its alias, keeping
+// any arguments. Like bash, an alias is never expanded twice in one line,
+// so `alias ls='ls -G'` and alias cycles terminate.
+func expandAliases(input string, aliases map[string]string) string {
+	expanded := map[string]bool{}
+	for {
+		trimmed := strings.TrimLeft(input, " \t")
+		name, rest, _ := strings.Cut(trimmed, " ")
+		cmd, ok := aliases[name]
+		if !ok || expanded[name] {
+			return input
+		}
+		expanded[name] = true
+		if rest != "" {
+			cmd += " " + rest
+		}
+		input = cmd
+	}
+}
+
+// runAliasBuiltin handles `alias` and `unalias`. It reports whether input
+// was one of them.
+func (m Model) runAliasBuiltin(input string) (string, bool) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(input), " ")
+	rest = strings.TrimSpace(rest)
+
+	switch name {
+	case "alias":
+		if rest == "" {
+			var sb strings.Builder
+			for _, n := range sortedNames(m.aliases) {
+				sb.WriteString(formatAlias(n, m.aliases[n]) + "\n")
+			}
+			return sb.String(), true
+		}
+		if !strings.Contains(rest, "=") {
+			if cmd, ok := m.aliases[rest]; ok {
+				return formatAlias(rest, cmd), true
+			}
+			return errStyle.Render(fmt.Sprintf("alias: %s: not found", rest)), true
+		}
+		n, cmd, ok := parseAlias(rest)
+		if !ok {
+			return errStyle.Render("usage: alias name='command'"), true
+		}
+		m.aliases[n] = cmd
+	case "unalias":
+		if _, ok := m.aliases[rest]; !ok {
+			return errStyle.Render(fmt.Sprintf("unalias: %s: not found", rest)), true
+		}
+		delete(m.aliases, rest)
+	default:
+		return "", false
+	}
+
+	if err := saveAliases(m.aliases); err != nil {
+		return errStyle.Render(fmt.Sprintf("Could not save aliases: %v", err)), true
+	}
+	return "", true
+}
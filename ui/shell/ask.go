@@ -0,0 +1,80 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"termiflow/demo"
+	"termiflow/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Natural Language Commands (? ...) --
+
+type commandSuggestedMsg struct {
+	command string
+	err     error
+}
+
+// askPrefix reports whether line is a question for Gemini ("? how do I …")
+// rather than a command, and returns the question.
+func askPrefix(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "?")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// askCommand asks Gemini for a command that does what question describes.
+// The answer is offered like a typo fix: shown, then run with ctrl+g.
+func (m *Model) askCommand(question string) tea.Cmd {
+	switch {
+	case question == "":
+		m.appendLine(dimStyle.Render("Describe what you want to do, e.g. ? list files by size"))
+		return nil
+	case !demo.Enabled() && !gemini.Configured():
+		msg := "? needs GEMINI_API_KEY (or GEMINI_BACKEND=vertex); use /setup in the Chat tab to add a key"
+		m.appendLine(errStyle.Render(msg))
+		m.lastErr = fmt.Sprintf("$ ? %s\n%s", question, msg)
+		return nil
+	}
+	m.appendLine(dimStyle.Render("Asking Gemini…"))
+	dir := m.currentDir
+	return func() tea.Msg {
+		if demo.Enabled() {
+			return commandSuggestedMsg{command: "ls -lS"}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+		prompt := fmt.Sprintf("Write one shell command for %s that does the following, run from %s. "+
+			"Reply with the command only: no explanation, no markdown.\n\n%s", runtime.GOOS, dir, question)
+		text, err := gemini.Generate(ctx, prompt)
+		if err != nil {
+			return commandSuggestedMsg{err: err}
+		}
+		command := firstCommand(text)
+		if command == "" {
+			return commandSuggestedMsg{err: errors.New("Gemini did not suggest a command")}
+		}
+		return commandSuggestedMsg{command: command}
+	}
+}
+
+// firstCommand pulls the command out of a reply that may still be wrapped
+// in a code fence, backticks or a "$ " prompt.
+func firstCommand(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		line = strings.Trim(line, "`")
+		return strings.TrimSpace(strings.TrimPrefix(line, "$ "))
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Streaming Execution --
+
+// execution is a running external command whose output is streamed into the
+// viewport line by line.
+type execution struct {
+	cmd     *exec.Cmd
+	line    string       // the command line as typed
+	msgs    chan tea.Msg // outputMsg values, then one commandDoneMsg
+	held    tea.Msg      // read by next while batching, returned by the following call
+	started time.Time
+	timed   bool // run as `time …`: report the timing when done
+}
+
+type outputMsg struct {
+	exec *execution
+	line string
+}
+
+type commandDoneMsg struct {
+	exec *execution
+	err  error
+}
+
+// startExecution starts cmd with stdout and stderr merged into one stream.
+func startExecution(cmd *exec.Cmd) (*execution, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	// Don't hang on children that inherit the pipe after a kill
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// Buffered so next can join lines that arrive faster than renders
+	e := &execution{cmd: cmd, msgs: make(chan tea.Msg, 1024), started: time.Now()}
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+	go func() {
+		raw := os.Getenv("TERMIFLOW_SHELL_BINARY") == "raw"
+		suppressed := 0
+		r := bufio.NewReaderSize(pr, maxLine)
+		for {
+			// ReadSlice bounds memory on huge lines; the slice is copied
+			// into the string before the next read reuses it
+			chunk, err := r.ReadSlice('\n')
+			if suppressed == 0 && (raw || !isBinary(chunk)) {
+				if len(chunk) > 0 {
+					e.msgs <- outputMsg{exec: e, line: string(chunk)}
+				}
+			} else {
+				suppressed += len(chunk)
+			}
+			if err != nil && err != bufio.ErrBufferFull {
+				break
+			}
+		}
+		if suppressed > 0 {
+			e.msgs <- outputMsg{exec: e, line: "\n" + dimStyle.Render(fmt.Sprintf("[binary output suppressed: %d bytes]", suppressed)) + "\n"}
+		}
+		e.msgs <- commandDoneMsg{exec: e, err: <-waitErr}
+		close(e.msgs)
+	}()
+	return e, nil
+}
+
+// maxLine is the longest chunk of output sent to the viewport at once.
+const maxLine = 64 * 1024
+
+// isBinary reports whether output looks like binary data rather than text:
+// it has a NUL byte, or more than 10% control characters or invalid UTF-8.
+// Tabs, newlines, carriage returns and ANSI escapes count as text.
+func isBinary(b []byte) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return true
+	}
+	bad, total := 0, 0
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		b = b[size:]
+		total++
+		switch {
+		case r == utf8.RuneError && size == 1:
+			bad++
+		case r == '\t', r == '\n', r == '\r', r == '\x1b':
+		case r < 0x20, r == 0x7f:
+			bad++
+		}
+	}
+	return total > 0 && bad*10 > total
+}
+
+// maxBatch caps how much already-waiting output next joins into one
+// message, so fast commands cost one render per batch instead of per line.
+const maxBatch = 256 * 1024
+
+// next waits for the execution's next message. Calls are sequential: Update
+// only asks for more once the previous message arrived.
+func (e *execution) next() tea.Cmd {
+	return func() tea.Msg {
+		msg := e.held
+		e.held = nil
+		if msg == nil {
+			var ok bool
+			if msg, ok = <-e.msgs; !ok {
+				return nil
+			}
+		}
+		out, ok := msg.(outputMsg)
+		if !ok {
+			return msg
+		}
+		var sb strings.Builder
+		sb.WriteString(out.line)
+		for sb.Len() < maxBatch {
+			select {
+			case more, ok := <-e.msgs:
+				if !ok {
+					return outputMsg{exec: e, line: sb.String()}
+				}
+				if o, isOutput := more.(outputMsg); isOutput {
+					sb.WriteString(o.line)
+					continue
+				}
+				e.held = more
+			default:
+			}
+			break
+		}
+		return outputMsg{exec: e, line: sb.String()}
+	}
+}
+
+// report describes the finished command for the on_command_done hook.
+func (e *execution) report(err error) map[string]any {
+	r := map[string]any{
+		"command":     strings.Join(e.cmd.Args, " "),
+		"dir":         e.cmd.Dir,
+		"exit_code":   e.cmd.ProcessState.ExitCode(),
+		"duration_ms": time.Since(e.started).Milliseconds(),
+	}
+	if err != nil {
+		r["error"] = err.Error()
+	}
+	return r
+}
+
+// interrupt kills the process. The caller drops the execution, so output
+// still in flight is drained but no longer shown.
+func (e *execution) interrupt() {
+	if e.cmd.Process != nil {
+		_ = e.cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,67 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestInterruptDropsLateOutput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := New()
+	m.SetSize(80, 20)
+
+	e, err := startExecution(exec.Command("sh", "-c", "echo started; sleep 10; echo finished"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.running = e
+	m, _ = m.Update(e.next()())
+	if !strings.Contains(m.content, "started") {
+		t.Fatalf("output before the interrupt is missing: %q", m.content)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if m.running != nil {
+		t.Fatal("the execution is still running after ctrl+c")
+	}
+	want := ansi.Strip(m.content)
+
+	// Output that was already on its way, then whatever the killed process
+	// still reports
+	m, _ = m.Update(outputMsg{exec: e, line: "late output\n"})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			msg := e.next()()
+			if msg == nil {
+				return
+			}
+			m, _ = m.Update(msg)
+			if _, ok := msg.(commandDoneMsg); ok {
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the interrupted command didn't finish")
+	}
+
+	got := ansi.Strip(m.content)
+	if got != want {
+		t.Errorf("content changed after the interrupt:\n%q\nwant\n%q", got, want)
+	}
+	if !strings.HasSuffix(strings.TrimRight(got, "\n"), "^C interrupted") {
+		t.Errorf("content doesn't end with the interrupt note: %q", got)
+	}
+	if m.lastErr != "" {
+		t.Errorf("lastErr = %q, want the interrupted command not reported as a failure", m.lastErr)
+	}
+}
@@ -0,0 +1,79 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"termiflow/demo"
+	"termiflow/gemini"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Explain Command (alt+e) --
+
+var explainStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#AF87FF")).
+	Padding(0, 1)
+
+const explainTimeout = 30 * time.Second
+
+// explanation is Gemini's take on a command, shown above the prompt until
+// esc or the command is run. Empty text and no error means it's loading.
+type explanation struct {
+	command string
+	text    string
+	err     error
+}
+
+type explainedMsg struct {
+	command string
+	text    string
+	err     error
+}
+
+// startExplain asks Gemini what the command at the prompt does, without
+// running it.
+func (m *Model) startExplain() tea.Cmd {
+	command := m.textInput.Value()
+	if command == "" {
+		return nil
+	}
+	m.explain = &explanation{command: command}
+	if !demo.Enabled() && !gemini.Configured() {
+		m.explain.err = errors.New("explaining commands needs GEMINI_API_KEY (or GEMINI_BACKEND=vertex)")
+		return nil
+	}
+	return explainCommand(command)
+}
+
+func explainCommand(command string) tea.Cmd {
+	return func() tea.Msg {
+		if demo.Enabled() {
+			return explainedMsg{command: command, text: fmt.Sprintf("Demo mode: Gemini would explain `%s` here.", command)}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+		prompt := "Explain what this shell command does, step by step but briefly (under 120 words). " +
+			"Warn clearly if it deletes, overwrites or sends data. Plain text, no markdown.\n\n" + command
+		text, err := gemini.Generate(ctx, prompt)
+		return explainedMsg{command: command, text: text, err: err}
+	}
+}
+
+func (m Model) explainView() string {
+	e := m.explain
+	body := "Asking Gemini…"
+	switch {
+	case e.err != nil:
+		body = errStyle.Render(fmt.Sprintf("Error: %v", e.err))
+	case e.text != "":
+		body = e.text
+	}
+	title := lipgloss.NewStyle().Bold(true).Render("$ " + e.command)
+	return explainStyle.Width(max(m.viewport.Width-2, 20)).Render(title + "\n" + body + "\n" + dimStyle.Render("esc close • enter run"))
+}
@@ -0,0 +1,122 @@
+package shell
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"termiflow/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Favorite Commands (alt+s, ctrl+j) --
+
+// favoritesFile stores starred commands in the order they were starred.
+const favoritesFile = "favorites.json"
+
+var (
+	favoritesStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#FFAF00")).
+			Padding(0, 1)
+	pickedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAF00")).Bold(true)
+)
+
+func loadFavorites() ([]string, error) {
+	var favorites []string
+	err := storage.LoadJSON(favoritesFile, &favorites)
+	return favorites, err
+}
+
+// toggleFavorite stars the command at the prompt, or the last command run
+// when the prompt is empty, and unstars it if it was already starred.
+func (m *Model) toggleFavorite() {
+	command := strings.TrimSpace(m.textInput.Value())
+	if command == "" && len(m.history) > 0 {
+		command = m.history[len(m.history)-1]
+	}
+	if command == "" {
+		return
+	}
+	note := "★ Starred: " + command + " (ctrl+j to pick)"
+	if i := slices.Index(m.favorites, command); i >= 0 {
+		m.favorites = slices.Delete(m.favorites, i, i+1)
+		note = "☆ Unstarred: " + command
+	} else {
+		m.favorites = append(m.favorites, command)
+	}
+	if err := storage.SaveJSON(favoritesFile, m.favorites); err != nil {
+		note = errStyle.Render(fmt.Sprintf("Could not save favorites: %v", err))
+	}
+	m.appendLine(dimStyle.Render(note))
+}
+
+// favoritePicker is the quick-pick overlay over the favorites.
+type favoritePicker struct {
+	cursor int
+}
+
+// updatePicker handles keys while the favorites are shown: ↑/↓ (or j/k)
+// move, enter or a digit puts the command at the prompt to run or edit,
+// d unstars it and esc closes.
+func (m Model) updatePicker(msg tea.KeyMsg) (Model, tea.Cmd) {
+	p := m.picker
+	switch s := msg.String(); s {
+	case "esc", "ctrl+j":
+		m.picker = nil
+	case "up", "k":
+		p.cursor = max(p.cursor-1, 0)
+	case "down", "j":
+		p.cursor = min(p.cursor+1, max(len(m.favorites)-1, 0))
+	case "d", "delete":
+		if len(m.favorites) == 0 {
+			break
+		}
+		m.favorites = slices.Delete(slices.Clone(m.favorites), p.cursor, p.cursor+1)
+		p.cursor = min(p.cursor, max(len(m.favorites)-1, 0))
+		if err := storage.SaveJSON(favoritesFile, m.favorites); err != nil {
+			m.appendLine(errStyle.Render(fmt.Sprintf("Could not save favorites: %v", err)))
+		}
+	case "enter", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		i := p.cursor
+		if s != "enter" {
+			i = int(s[0] - '1')
+		}
+		if i >= len(m.favorites) {
+			break
+		}
+		m.picker = nil
+		m.textInput.SetValue(m.favorites[i])
+		m.textInput.CursorEnd()
+		return m, m.focusInput()
+	}
+	return m, nil
+}
+
+// pickerView lists the favorites above the prompt, numbered for the digit
+// shortcuts.
+func (m Model) pickerView() string {
+	var sb strings.Builder
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Favorites") + "\n")
+	if len(m.favorites) == 0 {
+		sb.WriteString(dimStyle.Render("No favorites yet. Press alt+s to star the command at the prompt or the last one run.") + "\n")
+	}
+	width := max(m.viewport.Width-6, 20)
+	for i, command := range m.favorites {
+		line := fmt.Sprintf("  %s", command)
+		if i < 9 {
+			line = fmt.Sprintf("%d %s", i+1, command)
+		}
+		line = lipgloss.NewStyle().MaxWidth(width).Render(line)
+		if i == m.picker.cursor {
+			line = pickedStyle.Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(dimStyle.Render("enter or 1-9 use • d unstar • esc close"))
+	return favoritesStyle.Width(max(m.viewport.Width-2, 20)).Render(sb.String())
+}
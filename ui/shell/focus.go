@@ -0,0 +1,51 @@
+package shell
+
+import (
+	"termiflow/ui/focus"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Focus --
+
+// Focus is called when the Shell tab is shown and Blur when another tab
+// is, so long commands finishing out of sight can notify.
+func (m *Model) Focus() tea.Cmd { m.focused = true; return nil }
+func (m *Model) Blur()          { m.focused = false }
+func (m Model) Focused() bool   { return m.focused }
+
+// parts are the tab's focusable parts in tab order: the command line and
+// the output, navigated without typing. Only the focused one gets keys.
+func (m *Model) parts() []focus.Focusable {
+	return []focus.Focusable{&m.textInput, &m.output}
+}
+
+// focusInput gives focus back to the command line.
+func (m *Model) focusInput() tea.Cmd {
+	return focus.Set(&m.textInput, m.parts()...)
+}
+
+// updateOutput handles keys while the output is focused: vim-style keys
+// scroll it and esc/enter return to the command line. It reports false for
+// keys that should go through the usual handling (arrows, ctrl+s, ctrl+c…).
+func (m *Model) updateOutput(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "j":
+		m.viewport.ScrollDown(1)
+	case "k":
+		m.viewport.ScrollUp(1)
+	case "g", "home":
+		m.viewport.GotoTop()
+	case "G", "end":
+		m.viewport.GotoBottom()
+	case "ctrl+u":
+		m.viewport.HalfPageUp()
+	case "ctrl+d":
+		m.viewport.HalfPageDown()
+	case "esc", "enter", "i":
+		return m.focusInput(), true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
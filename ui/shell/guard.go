@@ -0,0 +1,96 @@
+package shell
+
+import (
+	"os"
+	"regexp"
+
+	"termiflow/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- Destructive Command Guard --
+
+// dangerous is a pattern that makes a command worth confirming first.
+type dangerous struct {
+	label string // shown in the confirmation
+	re    *regexp.Regexp
+}
+
+// defaultDangerous covers commands that delete or overwrite data with no
+// way back. TERMIFLOW_SHELL_DANGEROUS adds to it.
+var defaultDangerous = []dangerous{
+	{"rm -r", regexp.MustCompile(command + `rm\s+([^\s;&|]+\s+)*(-[a-zA-Z]*[rR]|--recursive\b)`)},
+	{"mkfs", regexp.MustCompile(command + `mkfs(\.\w+)?\b`)},
+	{"dd of=", regexp.MustCompile(command + `dd\b.*\bof=`)},
+	{"> /dev/", regexp.MustCompile(`>\s*/dev/(sd|hd|vd|nvme|mmcblk|disk)`)},
+	{"shred", regexp.MustCompile(command + `shred\b`)},
+	{"git reset --hard", regexp.MustCompile(command + `git\s+reset\s+([^\s;&|]+\s+)*--hard\b`)},
+	{"git clean -f", regexp.MustCompile(command + `git\s+clean\s+([^\s;&|]+\s+)*-[a-zA-Z]*f`)},
+	{"git push --force", regexp.MustCompile(command + `git\s+push\s+([^\s;&|]+\s+)*(--force\b|-f\b)`)},
+}
+
+// command anchors a pattern where a command name can start: the beginning
+// of the line, after a separator or a subshell, or after a wrapper like sudo.
+// It keeps `grep shred notes` and `echo rm -rf` from matching.
+const command = `(?:^|[;&|(\x60]|\b(?:sudo|xargs|exec|nohup|time|env)\b)\s*`
+
+// confirmEnabled reports whether destructive commands need a y first;
+// TERMIFLOW_SHELL_CONFIRM=0 turns the guard off.
+func confirmEnabled() bool {
+	return os.Getenv("TERMIFLOW_SHELL_CONFIRM") != "0"
+}
+
+// dangerousPatterns returns the defaults plus the regular expressions in
+// TERMIFLOW_SHELL_DANGEROUS (a JSON list, or one pattern). Invalid ones are
+// skipped; /config rejects them when set in-app.
+func dangerousPatterns() []dangerous {
+	patterns := defaultDangerous
+	extra, _ := config.Patterns(os.Getenv("TERMIFLOW_SHELL_DANGEROUS"))
+	for _, re := range extra {
+		patterns = append(patterns, dangerous{re.String(), re})
+	}
+	return patterns
+}
+
+// destructive returns the label of the first pattern the command matches,
+// as typed or with its alias expanded.
+func (m Model) destructive(line string) (string, bool) {
+	expanded := expandAliases(line, m.aliases)
+	for _, d := range dangerousPatterns() {
+		if d.re.MatchString(line) || d.re.MatchString(expanded) {
+			return d.label, true
+		}
+	}
+	return "", false
+}
+
+// pendingConfirm is a destructive command waiting for y or n.
+type pendingConfirm struct {
+	command string
+	label   string
+}
+
+// updateConfirm handles keys while a destructive command waits: y runs
+// it, n or esc puts it back at the prompt to edit.
+func (m Model) updateConfirm(msg tea.KeyMsg) (Model, tea.Cmd) {
+	c := m.confirm
+	switch msg.String() {
+	case "y", "Y":
+		m.confirm = nil
+		m.confirmed = c.command
+		m.textInput.SetValue(c.command)
+		return m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	case "n", "N", "esc":
+		m.confirm = nil
+		m.textInput.SetValue(c.command)
+		m.textInput.CursorEnd()
+	}
+	return m, nil
+}
+
+func (m Model) confirmView() string {
+	line := errStyle.Render("⚠ This looks destructive ("+m.confirm.label+") — run anyway? (y/n)") + " " + dimStyle.Render(m.confirm.command)
+	return lipgloss.NewStyle().MaxWidth(m.viewport.Width).Render(line)
+}
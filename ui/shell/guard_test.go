@@ -0,0 +1,54 @@
+package shell
+
+import "testing"
+
+func TestDestructive(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		aliases   map[string]string
+		dangerous string // TERMIFLOW_SHELL_DANGEROUS
+		want      string // label, "" for none
+	}{
+		{name: "rm -rf", line: "rm -rf x", want: "rm -r"},
+		{name: "flag after the path", line: "rm x -r", want: "rm -r"},
+		{name: "capital R", line: "rm -R build", want: "rm -r"},
+		{name: "long flag", line: "rm --recursive x", want: "rm -r"},
+		{name: "after a separator", line: "make clean && rm -rf dist", want: "rm -r"},
+		{name: "under sudo", line: "sudo rm -rf /tmp/x", want: "rm -r"},
+		{name: "from xargs", line: "find . -name '*.o' | xargs rm -r", want: "rm -r"},
+		{name: "rm -f", line: "rm -f report.txt"},
+		{name: "rm --force", line: "rm --force x"},
+		{name: "r in a file name", line: "rm -f r-r.txt"},
+		{name: "rm as an argument", line: "echo rm -rf x"},
+		{name: "shred as an argument", line: "grep shred f"},
+		{name: "shred", line: "shred -u secrets.txt", want: "shred"},
+		{name: "mkfs", line: "sudo mkfs.ext4 /dev/sdb1", want: "mkfs"},
+		{name: "dd", line: "dd if=img of=/dev/sdb", want: "dd of="},
+		{name: "dd without of", line: "dd if=/dev/zero bs=1M count=1"},
+		{name: "redirect to a disk", line: "cat img > /dev/sda", want: "> /dev/"},
+		{name: "git reset --hard", line: "git reset --hard HEAD~1", want: "git reset --hard"},
+		{name: "git reset --soft", line: "git reset --soft HEAD~1"},
+		{name: "git clean", line: "git clean -fdx", want: "git clean -f"},
+		{name: "git clean dry run", line: "git clean -n"},
+		{name: "git push -f", line: "git push origin main -f", want: "git push --force"},
+		{name: "git push", line: "git push origin main"},
+		{name: "alias expanded", line: "nuke build", aliases: map[string]string{"nuke": "rm -rf"}, want: "rm -r"},
+		{name: "alias of alias", line: "n build", aliases: map[string]string{"n": "nuke", "nuke": "rm -rf"}, want: "rm -r"},
+		{name: "harmless alias", line: "ll", aliases: map[string]string{"ll": "ls -l"}},
+		{name: "user pattern", line: "kubectl delete pod x", dangerous: `["kubectl delete", "terraform destroy"]`, want: "kubectl delete"},
+		{name: "user pattern with a comma", line: "drop a,b", dangerous: `["drop \\w+,\\w+"]`, want: `drop \w+,\w+`},
+		{name: "single user pattern", line: "terraform destroy", dangerous: "terraform destroy", want: "terraform destroy"},
+		{name: "invalid user pattern skipped", line: "kubectl delete pod x", dangerous: `["(", "kubectl delete"]`, want: "kubectl delete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERMIFLOW_SHELL_DANGEROUS", tt.dangerous)
+			m := Model{aliases: tt.aliases}
+			label, ok := m.destructive(tt.line)
+			if ok != (tt.want != "") || label != tt.want {
+				t.Errorf("destructive(%q) = %q, %v; want %q", tt.line, label, ok, tt.want)
+			}
+		})
+	}
+}
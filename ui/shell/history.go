@@ -0,0 +1,59 @@
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -- History Expansion --
+
+// expandHistory rewrites a leading `!!` (last command), `!n` (nth entry,
+// 1-based as shown by `history`) or `!prefix` (latest command starting with
+// prefix). Arguments after the event are kept, so `!! --verbose` works.
+func expandHistory(input string, history []string) (string, error) {
+	trimmed := strings.TrimSpace(input)
+	if !strings.HasPrefix(trimmed, "!") || len(trimmed) == 1 {
+		return input, nil
+	}
+	event, rest, _ := strings.Cut(trimmed, " ")
+	designator := event[1:]
+
+	var cmd string
+	switch n, err := strconv.Atoi(designator); {
+	case designator == "!":
+		if len(history) == 0 {
+			return "", fmt.Errorf("!!: event not found")
+		}
+		cmd = history[len(history)-1]
+	case err == nil:
+		if n < 1 || n > len(history) {
+			return "", fmt.Errorf("%s: event not found", event)
+		}
+		cmd = history[n-1]
+	default:
+		for i := len(history) - 1; i >= 0; i-- {
+			if strings.HasPrefix(history[i], designator) {
+				cmd = history[i]
+				break
+			}
+		}
+		if cmd == "" {
+			return "", fmt.Errorf("%s: event not found", event)
+		}
+	}
+
+	if rest != "" {
+		cmd += " " + rest
+	}
+	return cmd, nil
+}
+
+// formatHistory lists entries numbered for `!n`.
+func formatHistory(history []string) string {
+	var sb strings.Builder
+	for i, cmd := range history {
+		fmt.Fprintf(&sb, "%5d  %s\n", i+1, cmd)
+	}
+	return sb.String()
+}
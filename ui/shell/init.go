@@ -0,0 +1,48 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Startup Command --
+
+// initTimeout bounds TERMIFLOW_SHELL_INIT so a hung command can't leave the
+// welcome area waiting forever.
+const initTimeout = 10 * time.Second
+
+type initDoneMsg struct {
+	output string // prompt line, output and any error, ready to show
+}
+
+// runInitCommand runs TERMIFLOW_SHELL_INIT (e.g. "git status") once at
+// startup. Failures are reported in the output rather than stopping the app.
+func runInitCommand(dir string) tea.Cmd {
+	line := os.Getenv("TERMIFLOW_SHELL_INIT")
+	name, args, ok := parseCommand(line)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+
+		text := fmt.Sprintf("\n$ %s\n%s", line, out)
+		if err != nil {
+			if len(out) > 0 && out[len(out)-1] != '\n' {
+				text += "\n"
+			}
+			text += errStyle.Render(fmt.Sprintf("Startup command failed: %s", err)) + "\n"
+		}
+		return initDoneMsg{output: text}
+	}
+}
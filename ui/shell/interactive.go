@@ -0,0 +1,132 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"termiflow/hooks"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Interactive Commands --
+
+// Editors, pagers and full-screen programs need the real terminal, which
+// streaming through a pipe can't give them. They run with the UI suspended
+// instead, like the ctrl+o pager.
+var interactiveCommands = []string{
+	"vi", "vim", "nvim", "nano", "emacs", "micro", "pico",
+	"less", "more", "most", "man",
+	"top", "htop", "btop", "atop", "watch", "ncdu",
+	"ssh", "mosh", "tmux", "screen",
+	"lazygit", "tig", "fzf", "ranger", "nnn", "mc",
+}
+
+// repls are interactive only when started without arguments.
+var repls = []string{
+	"python", "python3", "node", "irb", "ghci", "lua",
+	"psql", "mysql", "sqlite3", "redis-cli",
+	"bash", "sh", "zsh", "fish",
+}
+
+// termPrefix marks a command as interactive by hand: `term ./setup.sh`.
+const termPrefix = "term"
+
+type interactiveDoneMsg struct {
+	exec *execution
+	err  error
+}
+
+// interactive reports whether a command needs the terminal. Besides the
+// known programs this covers git commands that open an editor or prompt,
+// and anything named in TERMIFLOW_INTERACTIVE (comma-separated).
+func interactive(name string, args []string) bool {
+	name = filepath.Base(name)
+	if slices.Contains(interactiveCommands, name) || slices.Contains(extraInteractive(), name) {
+		return true
+	}
+	if slices.Contains(repls, name) {
+		return len(args) == 0
+	}
+	if name == "git" && len(args) > 0 {
+		switch args[0] {
+		case "commit":
+			return !hasFlag(args[1:], "m", "message", "F", "file", "C", "reuse-message", "no-edit")
+		case "rebase":
+			return hasFlag(args[1:], "i", "interactive")
+		case "add", "checkout", "reset", "restore", "stash":
+			return hasFlag(args[1:], "p", "patch")
+		}
+	}
+	return false
+}
+
+// hasFlag reports whether args set any of the flags, given as single
+// letters (also found in clusters like -am) or long names (--name or
+// --name=value).
+func hasFlag(args []string, flags ...string) bool {
+	for _, a := range args {
+		if a == "--" {
+			return false
+		}
+		for _, f := range flags {
+			if len(f) == 1 {
+				if !strings.HasPrefix(a, "--") && strings.HasPrefix(a, "-") && strings.Contains(a[1:], f) {
+					return true
+				}
+			} else if a == "--"+f || strings.HasPrefix(a, "--"+f+"=") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func extraInteractive() []string {
+	var names []string
+	for _, n := range strings.Split(os.Getenv("TERMIFLOW_INTERACTIVE"), ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// runInteractive hands the terminal to cmd until it exits.
+//
+// A pseudo-terminal (creack/pty) isn't needed for this: the program gets
+// the real terminal, already a tty, while the UI is suspended. A pty would
+// only pay off for drawing such programs inside the output pane, which
+// would also take a terminal emulator to turn their escape sequences into
+// a screen; and creack/pty has no Windows support, where ExecProcess works.
+func runInteractive(cmd *exec.Cmd, line string, timed bool) tea.Cmd {
+	e := &execution{cmd: cmd, line: line, started: time.Now(), timed: timed}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return interactiveDoneMsg{e, err} })
+}
+
+// finishInteractive records how the command ended once the UI is back.
+// Its output went straight to the terminal, so there's none to keep.
+func (m *Model) finishInteractive(msg interactiveDoneMsg) {
+	e := msg.exec
+	hooks.Fire(hooks.CommandDone, e.report(msg.err))
+	m.lastRun = Run{Command: e.line, Err: msg.err}
+	switch {
+	case errors.Is(msg.err, exec.ErrNotFound):
+		m.appendLine(errStyle.Render(m.notFound(e.line, e.cmd.Args[0])))
+		m.lastErr = fmt.Sprintf("$ %s\ncommand not found: %s", e.line, e.cmd.Args[0])
+	case msg.err != nil:
+		m.appendLine(errStyle.Render(fmt.Sprintf("Error: %s", msg.err)))
+		m.lastErr = fmt.Sprintf("$ %s\nError: %s", e.line, msg.err)
+	default:
+		m.appendLine(dimStyle.Render(fmt.Sprintf("[%s exited after %s]", filepath.Base(e.cmd.Args[0]), time.Since(e.started).Round(time.Second))))
+	}
+	if e.timed && !errors.Is(msg.err, exec.ErrNotFound) {
+		m.appendLine(timingSummary(time.Since(e.started), e.cmd.ProcessState))
+	}
+}
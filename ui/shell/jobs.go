@@ -0,0 +1,135 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"termiflow/hooks"
+	"termiflow/notify"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// -- Background Jobs --
+
+// job is a command started with a trailing `&`. It streams like a
+// foreground command, but into its own buffer instead of the scrollback,
+// so the prompt stays free.
+type job struct {
+	id      int
+	exec    *execution
+	output  string // captured output, without colors; the tail past maxJobOutput
+	done    bool
+	err     error
+	elapsed time.Duration // run time, once done
+}
+
+// maxJobOutput caps how much of a job's output is kept for `jobs N`.
+const maxJobOutput = 256 * 1024
+
+// backgroundSuffix reports whether line ends in a lone `&` and returns it
+// without, so `make build &` runs `make build` as a job.
+func backgroundSuffix(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasSuffix(trimmed, "&") || strings.HasSuffix(trimmed, "&&") {
+		return line, false
+	}
+	return strings.TrimSpace(strings.TrimSuffix(trimmed, "&")), true
+}
+
+// startJob runs e in the background and announces its job number.
+func (m *Model) startJob(e *execution) tea.Cmd {
+	m.nextJob++
+	j := &job{id: m.nextJob, exec: e}
+	m.jobs = append(m.jobs, j)
+	m.appendLine(dimStyle.Render(fmt.Sprintf("[%d] started: %s", j.id, e.line)))
+	return e.next()
+}
+
+// jobFor returns the job running e, or nil for a foreground command.
+func (m Model) jobFor(e *execution) *job {
+	for _, j := range m.jobs {
+		if j.exec == e {
+			return j
+		}
+	}
+	return nil
+}
+
+// capture keeps a job's output and asks for more.
+func (j *job) capture(line string) tea.Cmd {
+	j.output += ansi.Strip(line)
+	if len(j.output) > maxJobOutput {
+		j.output = j.output[len(j.output)-maxJobOutput:]
+	}
+	return j.exec.next()
+}
+
+// finishJob records how a job ended and reports it below the output.
+func (m *Model) finishJob(j *job, err error) {
+	j.done, j.err, j.elapsed = true, err, time.Since(j.exec.started)
+	hooks.Fire(hooks.CommandDone, j.exec.report(err))
+	if !m.focused && j.elapsed >= notify.LongCommand() {
+		notify.Send("Job finished", fmt.Sprintf("[%d] %s\n%s after %s", j.id, j.exec.line, exitStatus(err), j.elapsed.Round(time.Second)))
+	}
+	m.lastRun = Run{Command: j.exec.line, Output: j.output, Err: err}
+	note := fmt.Sprintf("[%d] done (%s): %s — `jobs %d` shows its output", j.id, exitStatus(err), j.exec.line, j.id)
+	if err != nil {
+		m.lastErr = failureReport(j.exec.cmd, j.output, err)
+		m.appendLine(errStyle.Render(note))
+		return
+	}
+	m.appendLine(dimStyle.Render(note))
+}
+
+// jobsBuiltin handles `jobs` (list every job of the session) and `jobs N`
+// (print the captured output of job N).
+func (m Model) jobsBuiltin(args []string) string {
+	if len(args) == 0 {
+		if len(m.jobs) == 0 {
+			return "No background jobs. End a command with & to start one.\n"
+		}
+		var sb strings.Builder
+		for _, j := range m.jobs {
+			status := "running " + time.Since(j.exec.started).Round(time.Second).String()
+			if j.done {
+				status = fmt.Sprintf("done (%s) after %s", exitStatus(j.err), j.elapsed.Round(time.Second))
+			}
+			fmt.Fprintf(&sb, "[%d] %-28s %s\n", j.id, status, j.exec.line)
+		}
+		return sb.String()
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(args[0], "%"))
+	if err != nil || n < 1 || n > len(m.jobs) {
+		return errStyle.Render(fmt.Sprintf("jobs: %s: no such job", args[0])) + "\n"
+	}
+	j := m.jobs[n-1]
+	header := fmt.Sprintf("[%d] %s", j.id, j.exec.line)
+	if !j.done {
+		header += " (still running)"
+	}
+	out := j.output
+	if out == "" {
+		out = "(no output)\n"
+	} else if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return dimStyle.Render(header) + "\n" + out
+}
+
+// stopJobs kills the jobs still running, for quitting.
+func (m Model) stopJobs() {
+	for _, j := range m.jobs {
+		if !j.done {
+			j.exec.interrupt()
+		}
+	}
+}
+
+// errBackgroundInteractive refuses `vim &` and the like, which need the
+// terminal to themselves.
+var errBackgroundInteractive = errors.New("interactive commands can't run in the background")
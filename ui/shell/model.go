@@ -1,29 +1,88 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"termiflow/config"
+	"termiflow/hooks"
+	"termiflow/notify"
+	"termiflow/ui/focus"
+
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 var (
 	promptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF"))
 	errStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
 	pathStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00")).Bold(true)
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 )
 
+// enterRepeat is how soon after one Enter an Enter on an empty prompt counts
+// as the key being held down, and is ignored.
+const enterRepeat = 150 * time.Millisecond
+
 type Model struct {
 	viewport   viewport.Model
 	textInput  textinput.Model
 	currentDir string
+	startDir   string // directory the session started in, restored on reset
+	timestamps bool   // prefix prompt lines with the time (TERMIFLOW_SHELL_TIMESTAMPS=1)
+	wrap       bool   // soft-wrap long lines; off means scroll horizontally
+	aliases    map[string]string
+	history    []string       // commands run this session, after history expansion
+	prompts    []echoedPrompt // prompt lines in the scrollback, for Transcript
+	content    string         // full scrollback shown in the viewport
+	welcomeEnd int            // end of the welcome banner in content, where init output goes
+	running    *execution     // external command currently streaming, if any
+	busy       bool           // Enter was pressed while running; View says so
+	lastEnter  time.Time      // when Enter last submitted the prompt
+	jobs       []*job         // commands started with a trailing &, in order
+	nextJob    int            // number of the last job started
+	limit      int            // scrollback lines kept in content
+	trimmed    int            // lines dropped from the top of content
+	log        *outputLog     // full session output; nil when disabled
+	cmdStart   int            // where the running command's output starts in content
+	lastErr    string         // most recent failure, for copying
+	lastRun    Run            // most recent finished command, for filing as an issue
+	focused    bool           // the Shell tab is showing; see Focus
+	output     focus.Pane     // the output has focus instead of the command line
 	err        error
+
+	explain    *explanation // Gemini's take on the input (alt+e), nil when closed
+	suggestion string       // typo fix or Gemini's command for "? …", run with ctrl+g
+
+	confirm   *pendingConfirm // destructive command waiting for y/n, nil when none
+	confirmed string          // command the user just said y to, run unchecked
+
+	favorites []string        // starred commands (alt+s), in star order
+	picker    *favoritePicker // favorites overlay (ctrl+j), nil when closed
+
+	// Scrollback search (ctrl+s)
+	search      searchMode
+	searchInput textinput.Model
+	query       string
+	matches     []int // rendered line of each match
+	match       int   // index into matches
+}
+
+func welcomeBanner(dir string) string {
+	banner := fmt.Sprintf("Welcome to TermiFlow Shell!\nCurrent Directory: %s\n", dir)
+	if motd := os.Getenv("TERMIFLOW_SHELL_MOTD"); motd != "" {
+		banner += motd + "\n"
+	}
+	return banner
 }
 
 func New() Model {
@@ -35,18 +94,106 @@ func New() Model {
 	ti.CharLimit = 156
 	ti.Width = 20
 
-	vp := viewport.New(30, 20)
-	vp.SetContent(fmt.Sprintf("Welcome to TermiFlow Shell!\nCurrent Directory: %s\n", cwd))
+	vp := viewport.New(0, 0) // sized by the first SetSize, which wraps the welcome
+	vp.KeyMap = scrollKeys()
+	welcome := welcomeBanner(cwd)
+	aliases, err := loadAliases()
+	if err != nil {
+		welcome += errStyle.Render(fmt.Sprintf("Could not load aliases: %v", err)) + "\n"
+	}
+	favorites, err := loadFavorites()
+	if err != nil {
+		welcome += errStyle.Render(fmt.Sprintf("Could not load favorites: %v", err)) + "\n"
+	}
+	vp.SetContent(welcome)
 
 	return Model{
-		textInput:  ti,
-		viewport:   vp,
-		currentDir: cwd,
+		textInput:   ti,
+		viewport:    vp,
+		currentDir:  cwd,
+		startDir:    cwd,
+		timestamps:  os.Getenv("TERMIFLOW_SHELL_TIMESTAMPS") == "1",
+		wrap:        os.Getenv("TERMIFLOW_SHELL_WRAP") != "0",
+		aliases:     aliases,
+		favorites:   favorites,
+		content:     welcome,
+		welcomeEnd:  len(welcome),
+		limit:       scrollbackLimit(),
+		log:         newOutputLog(),
+		searchInput: newSearchInput(),
+	}
+}
+
+// ApplyConfig picks up shell settings changed by /reload.
+func (m *Model) ApplyConfig(changed config.Changes) tea.Cmd {
+	if !changed.Any("TERMIFLOW_SHELL_") {
+		return nil
+	}
+	m.timestamps = os.Getenv("TERMIFLOW_SHELL_TIMESTAMPS") == "1"
+	m.wrap = os.Getenv("TERMIFLOW_SHELL_WRAP") != "0"
+	m.limit = scrollbackLimit()
+	m.trim()
+	m.refresh()
+	return nil
+}
+
+// Running reports whether an external command is still streaming output.
+func (m Model) Running() bool {
+	return m.running != nil
+}
+
+// appendOutput adds s to the scrollback and the output log.
+func (m *Model) appendOutput(s string) {
+	m.log.write(s)
+	m.show(s)
+}
+
+// show adds s to the scrollback only, following the bottom unless the user
+// is searching.
+func (m *Model) show(s string) {
+	m.content += s
+	m.trim()
+	m.refresh()
+	if m.search == searchOff {
+		m.viewport.GotoBottom()
 	}
 }
 
+// trim drops the oldest lines beyond the scrollback limit.
+func (m *Model) trim() {
+	var cut, lines int
+	m.content, cut, lines = trimScrollback(m.content, m.limit)
+	m.trimmed += lines
+	m.welcomeEnd = max(m.welcomeEnd-cut, 0)
+	m.cmdStart = max(m.cmdStart-cut, 0)
+	m.prompts = shiftPrompts(m.prompts, 0, -cut)
+}
+
+// refresh re-renders the scrollback, wrapping it to the viewport if enabled.
+func (m *Model) refresh() {
+	content := m.content
+	if m.trimmed > 0 {
+		content = m.trimmedNote() + content
+	}
+	if m.wrap {
+		content = wrapOutput(content, m.viewport.Width)
+	}
+	if m.query != "" {
+		content, m.matches = highlightMatches(content, m.query)
+	}
+	m.viewport.SetContent(content)
+}
+
+// appendLine adds s on a line of its own.
+func (m *Model) appendLine(s string) {
+	if m.content != "" && !strings.HasSuffix(m.content, "\n") {
+		s = "\n" + s
+	}
+	m.appendOutput(s + "\n")
+}
+
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, runInitCommand(m.currentDir))
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
@@ -55,18 +202,145 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		vpCmd tea.Cmd
 	)
 
+	if key, ok := msg.(tea.KeyMsg); ok && m.search != searchOff {
+		if cmd, handled := m.updateSearch(key); handled {
+			return m, cmd
+		}
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && m.picker != nil {
+		return m.updatePicker(key)
+	}
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if s := key.String(); s == "tab" || s == "shift+tab" {
+			return m, focus.Cycle(s == "shift+tab", m.parts()...)
+		}
+		if m.output.Focused() {
+			if cmd, handled := m.updateOutput(key); handled {
+				return m, cmd
+			}
+		}
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && m.confirm != nil {
+		return m.updateConfirm(key)
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && m.running == nil {
+		switch key.String() {
+		case "ctrl+j":
+			m.picker = &favoritePicker{}
+			return m, nil
+		case "alt+s":
+			m.toggleFavorite()
+			return m, nil
+		}
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "alt+e" && m.running == nil {
+		return m, m.startExplain() // before the input would type the e
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && m.explain != nil {
+		switch key.Type {
+		case tea.KeyEsc:
+			m.explain = nil
+			return m, nil
+		case tea.KeyEnter:
+			m.explain = nil // running it; Enter goes on to execute
+		}
+	}
+
 	m.textInput, tiCmd = m.textInput.Update(msg)
 	m.viewport, vpCmd = m.viewport.Update(msg)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+s":
+			return m, m.startSearch()
+		case "alt+w":
+			m.wrap = !m.wrap
+			m.viewport.SetXOffset(0)
+			m.refresh()
+		case "alt+left":
+			m.viewport.ScrollLeft(horizontalStep)
+		case "alt+right":
+			m.viewport.ScrollRight(horizontalStep)
+		case "ctrl+o":
+			if m.running == nil {
+				return m, m.openLog()
+			}
+		case "ctrl+g":
+			if m.suggestion != "" && m.running == nil {
+				m.focusInput()
+				m.textInput.SetValue(m.suggestion)
+				return m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			}
+		}
+
 		switch msg.Type {
+		case tea.KeyCtrlC:
+			if m.running != nil {
+				m.running.interrupt()
+				m.running = nil
+				m.appendLine(errStyle.Render("^C interrupted"))
+			}
+		case tea.KeyCtrlN:
+			// Start a fresh session: original directory, clean viewport
+			if m.running != nil {
+				m.running.interrupt()
+				m.running = nil
+			}
+			m.currentDir = m.startDir
+			m.textInput.Reset()
+			welcome := welcomeBanner(m.startDir)
+			m.content = welcome + "\n[Session reset]\n"
+			m.welcomeEnd = len(welcome)
+			m.trimmed = 0
+			m.prompts = nil
+			m.log.write(m.content)
+			m.refresh()
+			m.viewport.GotoTop()
 		case tea.KeyEnter:
-			cmdStr := m.textInput.Value()
+			if m.running != nil {
+				m.busy = true // one command at a time
+				break
+			}
+			if m.textInput.Value() == "" && time.Since(m.lastEnter) < enterRepeat {
+				break // a held Enter; don't fill the output with empty prompts
+			}
+			m.lastEnter = time.Now()
+			input := m.textInput.Value()
 			m.textInput.Reset()
+			m.suggestion = ""
 
-			// Execute command
-			output, newDir := m.executeCommand(cmdStr)
+			// Expand !! / !n / !prefix; the prompt line shows the result
+			cmdStr, err := expandHistory(input, m.history)
+			if err != nil {
+				m.echo(input, m.currentDir, errStyle.Render(err.Error()))
+				m.lastErr = fmt.Sprintf("$ %s\n%s", input, err)
+				break
+			}
+			confirmed := cmdStr == m.confirmed
+			m.confirmed = ""
+			if label, ok := m.destructive(cmdStr); ok && !confirmed && confirmEnabled() {
+				m.confirm = &pendingConfirm{command: cmdStr, label: label}
+				break
+			}
+			if strings.TrimSpace(cmdStr) != "" {
+				m.history = append(m.history, cmdStr)
+			}
+			if question, ok := askPrefix(cmdStr); ok {
+				m.echo(cmdStr, m.currentDir, "")
+				return m, tea.Batch(tiCmd, vpCmd, m.askCommand(question))
+			}
+
+			// Execute command; a trailing & makes an external one a job
+			line, background := backgroundSuffix(cmdStr)
+			line, timed := timePrefix(line)
+			timed = timed && !background // a job reports when it's done instead
+			dir := m.currentDir
+			start := time.Now()
+			output, newDir, cmd, needsTerminal := m.executeCommand(line)
+			if timed && cmd == nil {
+				output += timingSummary(time.Since(start), nil) + "\n"
+			}
 
 			// Update directory if changed
 			if newDir != "" {
@@ -74,23 +348,223 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 
 			// Format output
-			prompt := fmt.Sprintf("%s $ %s", pathStyle.Render(filepath.Base(m.currentDir)), cmdStr)
-			newContent := fmt.Sprintf("%s\n%s\n%s", m.viewport.View(), prompt, output)
+			m.echo(cmdStr, dir, output)
+			if cmd == nil && strings.TrimSpace(cmdStr) != "" {
+				m.lastRun = Run{Command: cmdStr, Output: ansi.Strip(output)}
+			}
 
-			// Handle clearing screen separately if we wanted to
-			m.viewport.SetContent(newContent)
-			m.viewport.GotoBottom()
+			if cmd != nil && needsTerminal && background {
+				m.appendLine(errStyle.Render(errBackgroundInteractive.Error()))
+				break
+			}
+			if cmd != nil && needsTerminal {
+				return m, tea.Batch(tiCmd, vpCmd, runInteractive(cmd, cmdStr, timed))
+			}
+			if cmd != nil {
+				e, err := startExecution(cmd)
+				if errors.Is(err, exec.ErrNotFound) {
+					m.appendLine(errStyle.Render(m.notFound(cmdStr, cmd.Args[0])))
+					m.lastErr = fmt.Sprintf("$ %s\ncommand not found: %s", cmdStr, cmd.Args[0])
+					break
+				}
+				if err != nil {
+					m.appendLine(errStyle.Render(fmt.Sprintf("Error: %s", err)))
+					m.lastErr = fmt.Sprintf("$ %s\nError: %s", cmdStr, err)
+					break
+				}
+				e.line = cmdStr
+				e.timed = timed
+				if background {
+					e.line = line
+					return m, tea.Batch(tiCmd, vpCmd, m.startJob(e))
+				}
+				m.cmdStart = len(m.content)
+				m.running = e
+				m.busy = false
+				return m, tea.Batch(tiCmd, vpCmd, e.next())
+			}
+		}
+
+	case initDoneMsg:
+		m.content = m.content[:m.welcomeEnd] + msg.output + m.content[m.welcomeEnd:]
+		m.prompts = shiftPrompts(m.prompts, m.welcomeEnd, len(msg.output))
+		m.log.write(msg.output)
+		m.trim()
+		m.refresh()
+
+	case interactiveDoneMsg:
+		m.finishInteractive(msg)
+
+	case explainedMsg:
+		if m.explain != nil && m.explain.command == msg.command {
+			m.explain.text, m.explain.err = msg.text, msg.err
+		}
+
+	case commandSuggestedMsg:
+		if msg.err != nil {
+			m.appendLine(errStyle.Render(fmt.Sprintf("Error: %v", msg.err)))
+			m.lastErr = fmt.Sprintf("Error: %v", msg.err)
+			break
+		}
+		m.suggestion = msg.command
+		m.appendLine(fmt.Sprintf("%s  %s", promptStyle.Render(msg.command), dimStyle.Render("(ctrl+g runs it)")))
+
+	case logClosedMsg:
+		if msg.err != nil {
+			m.appendLine(errStyle.Render(fmt.Sprintf("Could not open output log: %v", msg.err)))
+		}
+
+	case outputMsg:
+		if j := m.jobFor(msg.exec); j != nil {
+			return m, j.capture(msg.line)
+		}
+		if msg.exec != m.running {
+			return m, msg.exec.next() // interrupted: drain without showing
+		}
+		m.appendOutput(msg.line)
+		return m, tea.Batch(tiCmd, vpCmd, msg.exec.next())
+
+	case commandDoneMsg:
+		if j := m.jobFor(msg.exec); j != nil {
+			m.finishJob(j, msg.err)
+			break
+		}
+		if msg.exec != m.running {
+			break // already marked interrupted
+		}
+		m.running = nil
+		hooks.Fire(hooks.CommandDone, msg.exec.report(msg.err))
+		if took := time.Since(msg.exec.started); !m.focused && took >= notify.LongCommand() {
+			notify.Send("Command finished", fmt.Sprintf("%s\n%s after %s", strings.Join(msg.exec.cmd.Args, " "), exitStatus(msg.err), took.Round(time.Second)))
+		}
+		m.lastRun = Run{Command: msg.exec.line, Output: ansi.Strip(m.content[m.cmdStart:]), Err: msg.err}
+		if msg.err != nil {
+			m.lastErr = failureReport(msg.exec.cmd, m.content[m.cmdStart:], msg.err)
+			m.appendLine(errStyle.Render(fmt.Sprintf("Error: %s", msg.err)))
+		}
+		if msg.exec.timed {
+			m.appendLine(timingSummary(time.Since(msg.exec.started), msg.exec.cmd.ProcessState))
 		}
-	case tea.WindowSizeMsg:
-		m.viewport.Width = msg.Width
-		m.textInput.Width = msg.Width
-		m.viewport.Height = msg.Height - 3 // Leave more room for input/header
 	}
 
 	return m, tea.Batch(tiCmd, vpCmd)
 }
 
+// Seed starts the session with text piped into the app: a single line is
+// left at the prompt to run or edit, anything longer is shown as output so
+// it can be searched and copied.
+func (m *Model) Seed(text string) {
+	text = strings.TrimRight(text, "\n")
+	if !strings.Contains(text, "\n") {
+		m.textInput.SetValue(text)
+		return
+	}
+	m.appendOutput(fmt.Sprintf("\n[stdin]\n%s\n", text))
+}
+
+// LastError returns the most recent failed command with the end of its
+// output, or "" if nothing failed.
+func (m Model) LastError() string { return m.lastErr }
+
+// Run is a finished command and what it printed.
+type Run struct {
+	Command string // as typed, after history expansion
+	Output  string // without colors; only what's left in the scrollback
+	Err     error  // nil if it succeeded
+}
+
+// LastRun returns the most recent finished command, if there is one.
+func (m Model) LastRun() (Run, bool) { return m.lastRun, m.lastRun.Command != "" }
+
+// errorTail is how many output lines of a failed command LastError keeps.
+const errorTail = 20
+
+// failureReport describes a failed command for pasting elsewhere: the
+// command line, the last lines it printed and the exit error, without
+// colors.
+func failureReport(cmd *exec.Cmd, output string, err error) string {
+	lines := strings.Split(strings.TrimRight(ansi.Strip(output), "\n"), "\n")
+	if len(lines) > errorTail {
+		lines = lines[len(lines)-errorTail:]
+	}
+	report := "$ " + strings.Join(cmd.Args, " ") + "\n"
+	if out := strings.Join(lines, "\n"); out != "" {
+		report += out + "\n"
+	}
+	return report + fmt.Sprintf("Error: %s", err)
+}
+
+// exitStatus summarizes how a command ended for notifications.
+func exitStatus(err error) string {
+	if err == nil {
+		return "exit 0"
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Sprintf("exit %d", exitErr.ExitCode())
+	}
+	return err.Error()
+}
+
+// promptLines echo cmd the way the prompt showed it in dir, naming the
+// directory's base, and again with the full directory.
+func (m Model) promptLines(cmd, dir string) (shown, full string) {
+	stamp := ""
+	if m.timestamps {
+		stamp = fmt.Sprintf("[%s] ", time.Now().Format("15:04:05"))
+	}
+	line := func(d string) string { return fmt.Sprintf("%s%s $ %s", stamp, pathStyle.Render(d), cmd) }
+	return line(filepath.Base(dir)), line(dir)
+}
+
+// SetSize fits the viewport and prompt line into width x height. Output
+// is re-wrapped to the new width; a view following the end keeps
+// following it, and one scrolled back stays about as far up.
+func (m *Model) SetSize(width, height int) {
+	atBottom, percent := m.viewport.AtBottom(), m.viewport.ScrollPercent()
+	m.viewport.Width = width
+	m.textInput.Width = width
+	m.viewport.Height = max(height-1, 0) // prompt line
+	m.refresh()
+	if atBottom {
+		m.viewport.GotoBottom()
+	} else {
+		m.viewport.SetYOffset(int(percent * float64(m.viewport.TotalLineCount()-m.viewport.Height)))
+	}
+}
+
 func (m Model) View() string {
+	switch m.search {
+	case searchTyping:
+		return fmt.Sprintf("%s\n%s", m.viewport.View(), m.searchInput.View())
+	case searchBrowsing:
+		return fmt.Sprintf("%s\n%s", m.viewport.View(), m.searchStatus())
+	}
+	if m.running != nil && m.busy {
+		return fmt.Sprintf("%s\n%s", m.viewport.View(), errStyle.Render("command already running")+dimStyle.Render(" (ctrl+c to stop)"))
+	}
+	if m.running != nil {
+		return fmt.Sprintf("%s\n%s", m.viewport.View(), dimStyle.Render("running… (ctrl+c to stop)"))
+	}
+	if m.confirm != nil {
+		return fmt.Sprintf("%s\n%s", m.viewport.View(), m.confirmView())
+	}
+	if m.output.Focused() {
+		return fmt.Sprintf("%s\n%s", m.viewport.View(), dimStyle.Render("output focused: ↑/↓ j/k g/G scroll • tab or esc back to the prompt"))
+	}
+	if m.picker != nil {
+		box := m.pickerView()
+		vp := m.viewport
+		vp.Height = max(vp.Height-lipgloss.Height(box), 1)
+		return fmt.Sprintf("%s\n%s\n%s $ %s", vp.View(), box, pathStyle.Render(filepath.Base(m.currentDir)), m.textInput.View())
+	}
+	if m.explain != nil {
+		// The explanation covers the bottom of the output
+		box := m.explainView()
+		vp := m.viewport
+		vp.Height = max(vp.Height-lipgloss.Height(box), 1)
+		return fmt.Sprintf("%s\n%s\n%s $ %s", vp.View(), box, pathStyle.Render(filepath.Base(m.currentDir)), m.textInput.View())
+	}
 	return fmt.Sprintf(
 		"%s\n%s $ %s",
 		m.viewport.View(),
@@ -99,44 +573,87 @@ func (m Model) View() string {
 	)
 }
 
-func (m Model) executeCommand(input string) (string, string) {
-	if strings.TrimSpace(input) == "" {
-		return "", ""
+// executeCommand runs builtins directly, returning their output and any new
+// directory. External commands are returned unstarted, along with whether
+// they need the terminal rather than streaming.
+func (m Model) executeCommand(input string) (string, string, *exec.Cmd, bool) {
+	if out, ok := m.runAliasBuiltin(input); ok {
+		return out, "", nil, false
+	}
+	if strings.TrimSpace(input) == "history" {
+		return formatHistory(m.history), "", nil, false
+	}
+	if name, args, ok := parseCommand(input); ok && name == "jobs" {
+		return m.jobsBuiltin(args), "", nil, false
+	}
+
+	cmdName, cmdArgs, ok := parseCommand(expandAliases(input, m.aliases))
+	if !ok {
+		return "", "", nil, false
+	}
+
+	if cmdName == "tree" {
+		return runTree(m.currentDir, cmdArgs), "", nil, false
 	}
-	parts := strings.Fields(input)
-	cmdName := parts[0]
-	cmdArgs := parts[1:]
 
 	// Handle 'cd' manually
 	if cmdName == "cd" {
-		targetDir := ""
-		if len(cmdArgs) > 0 {
-			targetDir = cmdArgs[0]
-		} else {
-			targetDir, _ = os.UserHomeDir()
-		}
-
-		// Handle relative paths
-		if !filepath.IsAbs(targetDir) {
-			targetDir = filepath.Join(m.currentDir, targetDir)
-		}
+		home, _ := os.UserHomeDir()
+		targetDir := cdTarget(m.currentDir, home, cmdArgs)
 
 		// Verify it exists
 		info, err := os.Stat(targetDir)
 		if err != nil || !info.IsDir() {
-			return errStyle.Render(fmt.Sprintf("cd: %s: No such directory", cmdArgs[0])), ""
+			shown := targetDir
+			if len(cmdArgs) > 0 {
+				shown = cmdArgs[0]
+			}
+			return errStyle.Render(fmt.Sprintf("cd: %s: No such directory", shown)), "", nil, false
 		}
 
-		return "", targetDir
+		return "", targetDir, nil, false
 	}
 
 	// External commands
+	forced := cmdName == termPrefix && len(cmdArgs) > 0
+	if forced {
+		cmdName, cmdArgs = cmdArgs[0], cmdArgs[1:]
+	}
 	cmd := exec.Command(cmdName, cmdArgs...)
 	cmd.Dir = m.currentDir
-	out, err := cmd.CombinedOutput()
+	return "", "", cmd, forced || interactive(cmdName, cmdArgs)
+}
 
-	if err != nil {
-		return errStyle.Render(fmt.Sprintf("Error: %s", err)), ""
+// parseCommand splits input into a command name and its arguments. It
+// reports false for blank input.
+func parseCommand(input string) (string, []string, bool) {
+	parts := strings.Fields(input)
+	if len(parts) == 0 {
+		return "", nil, false
+	}
+	return parts[0], parts[1:], true
+}
+
+// cdTarget resolves the directory `cd args...` should move to: home with no
+// arguments, otherwise the first argument relative to cwd.
+func cdTarget(cwd, home string, args []string) string {
+	if len(args) == 0 {
+		return home
+	}
+	if filepath.IsAbs(args[0]) {
+		return args[0]
+	}
+	return filepath.Join(cwd, args[0])
+}
+
+// scrollKeys limits the viewport to keys the prompt doesn't use. The
+// defaults include letters and readline keys like ctrl+u, which would scroll
+// the output while typing.
+func scrollKeys() viewport.KeyMap {
+	return viewport.KeyMap{
+		PageDown: key.NewBinding(key.WithKeys("pgdown")),
+		PageUp:   key.NewBinding(key.WithKeys("pgup")),
+		Up:       key.NewBinding(key.WithKeys("up")),
+		Down:     key.NewBinding(key.WithKeys("down")),
 	}
-	return string(out), ""
 }
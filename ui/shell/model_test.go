@@ -0,0 +1,43 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		name  string
+		args  []string
+		ok    bool
+	}{
+		{"", "", nil, false},
+		{"   \t", "", nil, false},
+		{"ls", "ls", []string{}, true},
+		{"  git   status  -s ", "git", []string{"status", "-s"}, true},
+	}
+	for _, tt := range tests {
+		name, args, ok := parseCommand(tt.input)
+		if name != tt.name || !reflect.DeepEqual(args, tt.args) || ok != tt.ok {
+			t.Errorf("parseCommand(%q) = %q, %q, %v; want %q, %q, %v", tt.input, name, args, ok, tt.name, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestCDTarget(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "/home/ana"},
+		{[]string{"src"}, "/work/proj/src"},
+		{[]string{".."}, "/work"},
+		{[]string{"/tmp", "ignored"}, "/tmp"},
+	}
+	for _, tt := range tests {
+		if got := cdTarget("/work/proj", "/home/ana", tt.args); got != tt.want {
+			t.Errorf("cdTarget(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
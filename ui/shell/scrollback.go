@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// -- Scrollback Limit and Output Log --
+
+// defaultScrollback is how many lines the viewport keeps unless
+// TERMIFLOW_SHELL_SCROLLBACK says otherwise. Re-rendering costs grow with
+// the scrollback, so huge outputs only keep their tail in memory.
+const defaultScrollback = 5000
+
+func scrollbackLimit() int {
+	if n, err := strconv.Atoi(os.Getenv("TERMIFLOW_SHELL_SCROLLBACK")); err == nil && n > 0 {
+		return n
+	}
+	return defaultScrollback
+}
+
+// trimScrollback keeps the last limit lines of s, returning the tail, the
+// number of bytes cut and the number of lines cut.
+func trimScrollback(s string, limit int) (string, int, int) {
+	extra := strings.Count(s, "\n") - limit
+	if extra <= 0 {
+		return s, 0, 0
+	}
+	cut := 0
+	for range extra {
+		cut += strings.IndexByte(s[cut:], '\n') + 1
+	}
+	return s[cut:], cut, extra
+}
+
+// outputLog records the whole session's output in a temp file, so nothing
+// trimmed from the scrollback is lost. It's shared by every copy of the
+// model; TERMIFLOW_SHELL_LOG=0 turns it off.
+type outputLog struct {
+	f   *os.File
+	err error // creating or writing the file failed; logging stops
+}
+
+func newOutputLog() *outputLog {
+	if os.Getenv("TERMIFLOW_SHELL_LOG") == "0" {
+		return nil
+	}
+	return &outputLog{}
+}
+
+// write appends s, creating the file on first use.
+func (l *outputLog) write(s string) {
+	if l == nil || l.err != nil {
+		return
+	}
+	if l.f == nil {
+		if l.f, l.err = os.CreateTemp("", "termiflow-shell-*.log"); l.err != nil {
+			return
+		}
+	}
+	_, l.err = l.f.WriteString(s)
+}
+
+// path is the log file's name, or "" when nothing was logged.
+func (l *outputLog) path() string {
+	if l == nil || l.f == nil {
+		return ""
+	}
+	return l.f.Name()
+}
+
+// remove deletes the log; it only lives as long as the session.
+func (l *outputLog) remove() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	l.f.Close()
+	err := os.Remove(l.f.Name())
+	l.f = nil
+	return err
+}
+
+type logClosedMsg struct{ err error }
+
+// openLog shows the full output log in $PAGER (default less -R), handing
+// the terminal over until it exits.
+func (m Model) openLog() tea.Cmd {
+	path := m.log.path()
+	if path == "" {
+		return nil
+	}
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R"}
+	}
+	cmd := exec.Command(pager[0], append(pager[1:], path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return logClosedMsg{err} })
+}
+
+// trimmedNote heads the scrollback once older lines were dropped.
+func (m Model) trimmedNote() string {
+	note := fmt.Sprintf("[%d earlier lines not shown", m.trimmed)
+	if path := m.log.path(); path != "" {
+		note += fmt.Sprintf("; full output in %s, ctrl+o to open", path)
+	}
+	return dimStyle.Render(note+"]") + "\n"
+}
+
+// Shutdown stops background jobs and deletes the session's output log.
+func (m Model) Shutdown() error {
+	m.stopJobs()
+	return m.log.remove()
+}
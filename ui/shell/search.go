@@ -0,0 +1,133 @@
+package shell
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// -- Scrollback Search --
+
+type searchMode int
+
+const (
+	searchOff      searchMode = iota
+	searchTyping              // editing the query
+	searchBrowsing            // stepping through matches with n/N
+)
+
+var matchStyle = lipgloss.NewStyle().Background(lipgloss.Color("#FFD700")).Foreground(lipgloss.Color("#000000"))
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search output"
+	ti.CharLimit = 100
+	return ti
+}
+
+// startSearch opens the query prompt, blurring the command line.
+func (m *Model) startSearch() tea.Cmd {
+	m.search = searchTyping
+	m.textInput.Blur()
+	m.searchInput.SetValue(m.query)
+	m.searchInput.CursorEnd()
+	return m.searchInput.Focus()
+}
+
+// stopSearch clears the query and highlights and returns to the prompt.
+func (m *Model) stopSearch() tea.Cmd {
+	m.search = searchOff
+	m.query = ""
+	m.matches = nil
+	m.searchInput.Blur()
+	m.refresh()
+	return m.focusInput()
+}
+
+// updateSearch handles keys while searching. It reports false when the key
+// should fall through to the command line instead.
+func (m *Model) updateSearch(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.search == searchTyping {
+		switch msg.Type {
+		case tea.KeyEsc:
+			return m.stopSearch(), true
+		case tea.KeyCtrlC:
+			return m.stopSearch(), false // still interrupts a running command
+		case tea.KeyEnter:
+			m.query = m.searchInput.Value()
+			if m.query == "" {
+				return m.stopSearch(), true
+			}
+			m.search = searchBrowsing
+			m.searchInput.Blur()
+			m.refresh()
+			m.match = len(m.matches) - 1 // most recent output first
+			m.showMatch()
+			return nil, true
+		}
+		var cmd tea.Cmd
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		return cmd, true
+	}
+
+	switch msg.String() {
+	case "n":
+		m.stepMatch(1)
+	case "N":
+		m.stepMatch(-1)
+	case "/", "ctrl+s":
+		return m.startSearch(), true
+	case "esc":
+		return m.stopSearch(), true
+	default:
+		// Anything else goes back to typing commands
+		return m.stopSearch(), false
+	}
+	return nil, true
+}
+
+func (m *Model) stepMatch(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (m.match + delta + len(m.matches)) % len(m.matches)
+	m.showMatch()
+}
+
+// showMatch scrolls the current match to the middle of the viewport.
+func (m *Model) showMatch() {
+	if m.match < 0 || m.match >= len(m.matches) {
+		return
+	}
+	m.viewport.SetYOffset(m.matches[m.match] - m.viewport.Height/2)
+}
+
+// highlightMatches marks case-insensitive occurrences of query in content
+// and returns the indices of the lines containing one.
+func highlightMatches(content, query string) (string, []int) {
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	lines := strings.Split(content, "\n")
+	var matches []int
+	for i, line := range lines {
+		if !re.MatchString(ansi.Strip(line)) {
+			continue
+		}
+		matches = append(matches, i)
+		lines[i] = re.ReplaceAllStringFunc(line, func(s string) string { return matchStyle.Render(s) })
+	}
+	return strings.Join(lines, "\n"), matches
+}
+
+// searchStatus replaces the prompt line while browsing matches.
+func (m Model) searchStatus() string {
+	if len(m.matches) == 0 {
+		return dimStyle.Render(fmt.Sprintf("no matches for %q • esc close", m.query))
+	}
+	return dimStyle.Render(fmt.Sprintf("match %d/%d for %q • n next • N previous • esc close", m.match+1, len(m.matches), m.query))
+}
@@ -0,0 +1,99 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// -- Did You Mean --
+
+// builtins are the commands the shell runs itself rather than from $PATH.
+var builtins = []string{"cd", "history", "alias", "unalias", "tree", "jobs", "time"}
+
+// notFound explains a command missing from $PATH and, for a likely typo,
+// names the closest known command. When the fix applies to what was typed
+// it is remembered so ctrl+g can run it.
+func (m *Model) notFound(line, name string) string {
+	msg := "command not found: " + name
+	best, ok := closestCommand(name, m.aliases)
+	if !ok {
+		return msg
+	}
+	msg += fmt.Sprintf(". Did you mean %s?", best)
+	if first, rest, _ := strings.Cut(strings.TrimSpace(line), " "); first == name {
+		m.suggestion = strings.TrimSpace(best + " " + rest)
+		msg += fmt.Sprintf(" (ctrl+g runs `%s`)", m.suggestion)
+	}
+	return msg
+}
+
+// closestCommand finds the builtin, alias or $PATH executable nearest to
+// name. Short names allow one edit, longer ones two.
+func closestCommand(name string, aliases map[string]string) (string, bool) {
+	limit := max(1, min(2, len(name)/3))
+	best, bestDist := "", limit+1
+	consider := func(c string) {
+		if d := editDistance(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	for _, c := range builtins {
+		consider(c)
+	}
+	for c := range aliases {
+		consider(c)
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			c := e.Name()
+			if abs(len(c)-len(name)) > limit {
+				continue // can't be close enough; skip the stat
+			}
+			if info, err := os.Stat(filepath.Join(dir, c)); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+				consider(c)
+			}
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance is the Levenshtein distance between a and b, counting a
+// swap of adjacent characters ("gti" for "git") as one edit.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	// d[i][j] is the distance between s[:i] and t[:j]
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,34 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// -- time Builtin --
+
+// timePrefix strips a leading `time` from line, reporting whether there was
+// one. A bare `time` is left alone, so it runs the system's.
+func timePrefix(line string) (string, bool) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(line), " ")
+	if !ok || name != "time" || strings.TrimSpace(rest) == "" {
+		return line, false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// timingSummary reports a timed command's wall-clock time and, for an
+// external one that exited, its CPU time, like the shell's own `time`.
+func timingSummary(real time.Duration, state *os.ProcessState) string {
+	summary := fmt.Sprintf("real %s", formatSeconds(real))
+	if state != nil {
+		summary += fmt.Sprintf("  user %s  sys %s", formatSeconds(state.UserTime()), formatSeconds(state.SystemTime()))
+	}
+	return dimStyle.Render(summary)
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
@@ -0,0 +1,65 @@
+package shell
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// -- Session Transcript --
+
+// echoedPrompt is a prompt line in the scrollback, which names only the
+// base of the directory, and the line naming the directory in full.
+type echoedPrompt struct {
+	at, n int    // offset and length of the shown line in content
+	full  string // without colors
+}
+
+// echo adds the prompt line for cmd run in dir, followed by rest. The output
+// log gets the line with the full directory; the scrollback keeps the
+// short one and remembers where, so Transcript can swap it.
+func (m *Model) echo(cmd, dir, rest string) {
+	shown, full := m.promptLines(cmd, dir)
+	m.log.write("\n" + full + "\n" + rest)
+	m.prompts = append(m.prompts, echoedPrompt{at: len(m.content) + 1, n: len(shown), full: ansi.Strip(full)})
+	m.show("\n" + shown + "\n" + rest)
+}
+
+// shiftPrompts moves the prompts at or after offset from by delta, after
+// text was inserted there or, with a negative delta, cut from the start.
+// Prompts cut with it are dropped.
+func shiftPrompts(prompts []echoedPrompt, from, delta int) []echoedPrompt {
+	if delta == 0 {
+		return prompts
+	}
+	var kept []echoedPrompt
+	for _, p := range prompts {
+		if p.at >= from {
+			p.at += delta
+		}
+		if p.at >= 0 {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// Transcript returns the whole session, commands and output, without
+// colors and with each prompt line naming the full directory its command
+// ran in. It reads the output log when there is one, so lines trimmed from
+// the scrollback are included.
+func (m Model) Transcript() string {
+	text := m.content
+	if path := m.log.path(); path != "" {
+		if b, err := os.ReadFile(path); err == nil {
+			text = string(b)
+		}
+	} else {
+		for _, p := range slices.Backward(m.prompts) {
+			text = text[:p.at] + p.full + text[p.at+p.n:]
+		}
+	}
+	return strings.TrimSpace(ansi.Strip(text)) + "\n"
+}
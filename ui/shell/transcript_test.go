@@ -0,0 +1,31 @@
+package shell
+
+import "testing"
+
+func TestTranscript(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		init  string // output of the startup commands, arriving after the prompts
+		want  string
+	}{
+		{"every prompt", 100, "", "welcome\n\n/home/me/app $ ls\na b\n\n/tmp $ pwd\n/tmp"},
+		{"after the first was trimmed", 3, "", "/tmp $ pwd\n/tmp"},
+		{"trimmed mid-output", 4, "", "a b\n\n/tmp $ pwd\n/tmp"},
+		{"startup output inserted before", 100, "init\n", "welcome\ninit\n\n/home/me/app $ ls\na b\n\n/tmp $ pwd\n/tmp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{content: "welcome\n", welcomeEnd: len("welcome\n"), limit: tt.limit}
+			m.echo("ls", "/home/me/app", "a b\n")
+			m.echo("pwd", "/tmp", "/tmp\n")
+			if tt.init != "" {
+				m.content = m.content[:m.welcomeEnd] + tt.init + m.content[m.welcomeEnd:]
+				m.prompts = shiftPrompts(m.prompts, m.welcomeEnd, len(tt.init))
+			}
+			if got := m.Transcript(); got != tt.want+"\n" {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want+"\n")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// -- tree Builtin --
+
+var (
+	treeDirStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5F87FF")).Bold(true)
+	treeLinkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00AFAF"))
+)
+
+const (
+	defaultTreeDepth = 3
+	maxTreeEntries   = 500 // keeps a huge directory from flooding the scrollback
+)
+
+// treeDepth returns TERMIFLOW_TREE_DEPTH, the default for `tree` without -L.
+func treeDepth() int {
+	if n, err := strconv.Atoi(os.Getenv("TERMIFLOW_TREE_DEPTH")); err == nil && n > 0 {
+		return n
+	}
+	return defaultTreeDepth
+}
+
+// treeIgnored returns the directory names `tree` doesn't descend into:
+// TERMIFLOW_TREE_IGNORE (comma-separated), or node_modules and .git.
+func treeIgnored() []string {
+	v, ok := os.LookupEnv("TERMIFLOW_TREE_IGNORE")
+	if !ok {
+		return []string{"node_modules", ".git"}
+	}
+	var names []string
+	for _, n := range strings.Split(v, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// runTree renders `tree [-L depth] [dir]` relative to cwd.
+func runTree(cwd string, args []string) string {
+	depth, dir := treeDepth(), "."
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-L" && i+1 < len(args):
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return errStyle.Render(fmt.Sprintf("tree: invalid level %q", args[i+1]))
+			}
+			depth = n
+			i++
+		case strings.HasPrefix(args[i], "-"):
+			return errStyle.Render("usage: tree [-L depth] [dir]")
+		default:
+			dir = args[i]
+		}
+	}
+	root := dir
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(cwd, root)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return errStyle.Render(fmt.Sprintf("tree: %s: No such directory", dir))
+	}
+
+	t := treeWalk{ignored: treeIgnored(), depth: depth}
+	t.sb.WriteString(treeDirStyle.Render(dir) + "\n")
+	t.walk(root, "", 1)
+	summary := fmt.Sprintf("%d directories, %d files", t.dirs, t.files)
+	if t.truncated {
+		summary += fmt.Sprintf(" (stopped at %d entries)", maxTreeEntries)
+	}
+	return t.sb.String() + "\n" + dimStyle.Render(summary) + "\n"
+}
+
+type treeWalk struct {
+	sb          strings.Builder
+	ignored     []string
+	depth       int
+	dirs, files int
+	truncated   bool
+}
+
+func (t *treeWalk) walk(dir, prefix string, level int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.sb.WriteString(prefix + errStyle.Render(fmt.Sprintf("[%v]", err)) + "\n")
+		return
+	}
+	for i, e := range entries {
+		if t.dirs+t.files >= maxTreeEntries {
+			t.truncated = true
+			return
+		}
+		branch, indent := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := e.Name()
+		switch {
+		case e.IsDir():
+			t.dirs++
+			if slices.Contains(t.ignored, name) {
+				t.sb.WriteString(prefix + branch + treeDirStyle.Render(name) + dimStyle.Render(" (skipped)") + "\n")
+				continue
+			}
+			t.sb.WriteString(prefix + branch + treeDirStyle.Render(name) + "\n")
+			if level < t.depth {
+				t.walk(filepath.Join(dir, name), prefix+indent, level+1)
+			}
+		case e.Type()&os.ModeSymlink != 0:
+			t.files++
+			target, _ := os.Readlink(filepath.Join(dir, name))
+			t.sb.WriteString(prefix + branch + treeLinkStyle.Render(name) + " -> " + target + "\n")
+		default:
+			t.files++
+			t.sb.WriteString(prefix + branch + name + "\n")
+		}
+		if t.truncated {
+			return
+		}
+	}
+}
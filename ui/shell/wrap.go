@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// -- Line Wrapping --
+
+// continuation marks the lines a long output line was wrapped onto.
+const continuation = "↪ "
+
+// horizontalStep is how many columns alt+left/alt+right scroll when
+// wrapping is off.
+const horizontalStep = 8
+
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;:]*m`)
+
+// wrapOutput soft-wraps every line of s wider than width. Continuation
+// lines start with a marker, and colors active at a break are re-applied on
+// the next line so spans aren't cut off.
+func wrapOutput(s string, width int) string {
+	if width <= len(continuation) {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if ansi.StringWidth(line) <= width {
+			sb.WriteString(line)
+			continue
+		}
+		segments := []string{ansi.Truncate(line, width, "")}
+		rest := ansi.TruncateLeft(line, width, "")
+		segments = append(segments, strings.Split(ansi.Hardwrap(rest, width-ansi.StringWidth(continuation), true), "\n")...)
+
+		active := ""
+		for j, seg := range segments {
+			if j > 0 {
+				sb.WriteString("\n" + continuation + active)
+			}
+			sb.WriteString(seg)
+			active = activeSGR(active, seg)
+			if active != "" {
+				sb.WriteString("\x1b[0m")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// activeSGR returns the color/style sequences still in effect after seg,
+// given those active before it.
+func activeSGR(active, seg string) string {
+	for _, seq := range sgrPattern.FindAllString(seg, -1) {
+		if seq == "\x1b[m" || seq == "\x1b[0m" {
+			active = ""
+		} else {
+			active += seq
+		}
+	}
+	return active
+}
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"os"
+	"strings"
+)
+
+// Seed starts the app with text piped into stdin. It becomes the first
+// chat prompt, or shell input when the app opens on the Shell tab or
+// TERMIFLOW_STDIN=shell; TERMIFLOW_STDIN=chat always picks the chat. What
+// the chat schedules for the prompt runs from Init.
+func (m Model) Seed(text string) Model {
+	if strings.TrimSpace(text) == "" {
+		return m
+	}
+	target := strings.ToLower(os.Getenv("TERMIFLOW_STDIN"))
+	if target == "shell" || (target == "" && m.state == viewShell) {
+		m.state = viewShell
+		m.shell.Seed(text)
+		return m
+	}
+	m.state = viewChat
+	m.seeded = m.chat.Prefill(strings.TrimRight(text, "\n"))
+	return m
+}
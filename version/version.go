@@ -0,0 +1,18 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X termiflow/version.Version=v0.2.0 -X termiflow/version.BuildDate=2024-05-01"
+var (
+	Version   = "dev"
+	BuildDate = "unknown"
+)
+
+func String() string {
+	return fmt.Sprintf("termiflow %s (built %s, %s)", Version, BuildDate, runtime.Version())
+}